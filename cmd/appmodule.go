@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"sort"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+var errBootstrapNotFound = errors.New("no se encontró el método Bootstrap en app.module.go")
+
+// registerModule añade a Bootstrap el import y la llamada que monta las rutas
+// del módulo. Si ya están no hace nada.
+func registerModule(data crudData) error {
+	src, err := os.ReadFile(config.appModulePath())
+	if err != nil {
+		return err
+	}
+	out, err := addModuleRegistration(src, data.ModuleImport, data.Package, data.ClassName)
+	if err != nil {
+		return err
+	}
+	return writer.UpdateFile(config.appModulePath(), out)
+}
+
+// unregisterModule deshace registerModule; devuelve false si el módulo no
+// estaba registrado.
+func unregisterModule(moduleName string) (bool, error) {
+	src, err := os.ReadFile(config.appModulePath())
+	if err != nil {
+		return false, err
+	}
+	out, err := removeModuleRegistration(src, config.moduleImportPath(moduleName))
+	if err != nil || out == nil {
+		return false, err
+	}
+	return true, writer.UpdateFile(config.appModulePath(), out)
+}
+
+func removeModule(name string, deleteFiles bool) {
+	n, err := newNames(name)
+	if err != nil {
+		failf("%v", err)
+		return
+	}
+	moduleName := n.Dir
+	removed, err := unregisterModule(moduleName)
+	switch {
+	case err != nil:
+		failf("no se pudo quitar el módulo de %s: %v", config.appModulePath(), err)
+		return
+	case removed:
+		fmt.Printf("✅ Módulo %s quitado de %s\n", moduleName, config.appModulePath())
+	default:
+		fmt.Printf("ℹ️  El módulo %s no estaba registrado en %s\n", moduleName, config.appModulePath())
+	}
+
+	if deleteFiles {
+		writer.RemoveAll(config.modulePath(moduleName))
+	}
+}
+
+func addModuleRegistration(src []byte, importPath, pkg, class string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "app.module.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap := findBootstrap(file)
+	if bootstrap == nil {
+		return nil, errBootstrapNotFound
+	}
+
+	// Si el paquete ya se importa se respeta su nombre; si no, se evita que
+	// choque con el receptor, los parámetros o los demás imports.
+	name, imported := importName(file, importPath)
+	if !imported {
+		name = pkg
+		if declaresName(bootstrap, name) || importsName(file, name) {
+			name = pkg + "module"
+		}
+	}
+
+	if !imported || !referencesPackage(bootstrap, bootstrap.Body.List, name) {
+		receiver := "app"
+		if names := bootstrap.Recv.List[0].Names; len(names) > 0 {
+			receiver = names[0].Name
+		}
+		call := fmt.Sprintf("\t%s.New%sModule().RegisterRoutes(%s.Core.Router)\n", name, class, receiver)
+
+		// La llamada se inserta antes del return final (o de la llave de
+		// cierre) para conservar los comentarios del cuerpo.
+		at := bootstrap.Body.Rbrace
+		if n := len(bootstrap.Body.List); n > 0 {
+			if ret, ok := bootstrap.Body.List[n-1].(*ast.ReturnStmt); ok {
+				at = ret.Pos()
+			}
+		}
+		offset := lineStart(src, fset.Position(at).Offset)
+		src = append(src[:offset:offset], append([]byte(call), src[offset:]...)...)
+
+		fset = token.NewFileSet()
+		if file, err = parser.ParseFile(fset, "app.module.go", src, parser.ParseComments); err != nil {
+			return nil, err
+		}
+	}
+
+	if name != path.Base(importPath) {
+		astutil.AddNamedImport(fset, file, name, importPath)
+	} else {
+		astutil.AddImport(fset, file, importPath)
+	}
+	return formatFile(fset, file)
+}
+
+// removeModuleRegistration devuelve nil si el módulo no está importado.
+func removeModuleRegistration(src []byte, importPath string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "app.module.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap := findBootstrap(file)
+	if bootstrap == nil {
+		return nil, errBootstrapNotFound
+	}
+
+	var spec *ast.ImportSpec
+	for _, s := range file.Imports {
+		if importValue(s) == importPath {
+			spec = s
+		}
+	}
+	if spec == nil {
+		return nil, nil
+	}
+	name, _ := importName(file, importPath)
+
+	// Se borran las líneas completas de cada sentencia que usa el paquete,
+	// de la última a la primera para no invalidar los offsets.
+	var ranges [][2]int
+	for _, stmt := range bootstrap.Body.List {
+		if referencesPackage(bootstrap, []ast.Stmt{stmt}, name) {
+			start := lineStart(src, fset.Position(stmt.Pos()).Offset)
+			end := fset.Position(stmt.End()).Offset
+			if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+				end += i + 1
+			}
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] > ranges[j][0] })
+	for _, r := range ranges {
+		src = append(src[:r[0]:r[0]], src[r[1]:]...)
+	}
+
+	fset = token.NewFileSet()
+	if file, err = parser.ParseFile(fset, "app.module.go", src, parser.ParseComments); err != nil {
+		return nil, err
+	}
+	if spec.Name != nil {
+		astutil.DeleteNamedImport(fset, file, spec.Name.Name, importPath)
+	} else {
+		astutil.DeleteImport(fset, file, importPath)
+	}
+	return formatFile(fset, file)
+}
+
+func findBootstrap(file *ast.File) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Name.Name == "Bootstrap" && fn.Recv != nil && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// importName devuelve el nombre con el que file importa importPath.
+func importName(file *ast.File, importPath string) (string, bool) {
+	for _, spec := range file.Imports {
+		if importValue(spec) != importPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, true
+		}
+		return path.Base(importPath), true
+	}
+	return "", false
+}
+
+func importsName(file *ast.File, name string) bool {
+	for _, spec := range file.Imports {
+		if (spec.Name != nil && spec.Name.Name == name) || (spec.Name == nil && path.Base(importValue(spec)) == name) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaresName indica si el receptor o los parámetros de fn se llaman name,
+// lo que oculta cualquier import con ese nombre dentro de la función.
+func declaresName(fn *ast.FuncDecl, name string) bool {
+	for _, fields := range []*ast.FieldList{fn.Recv, fn.Type.Params} {
+		if fields == nil {
+			continue
+		}
+		for _, field := range fields.List {
+			for _, ident := range field.Names {
+				if ident.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// referencesPackage indica si alguna sentencia de fn usa un selector sobre
+// el paquete importado como pkg (pkg.Algo). Un receptor o parámetro con el
+// mismo nombre (app.Core) no es el paquete.
+func referencesPackage(fn *ast.FuncDecl, stmts []ast.Stmt, pkg string) bool {
+	if declaresName(fn, pkg) {
+		return false
+	}
+	found := false
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == pkg {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+func importValue(spec *ast.ImportSpec) string {
+	return spec.Path.Value[1 : len(spec.Path.Value)-1]
+}
+
+func lineStart(src []byte, offset int) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+func formatFile(fset *token.FileSet, file *ast.File) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
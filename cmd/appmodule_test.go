@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testAppModule = `package src
+
+import (
+	"shop/config"
+)
+
+type AppModule struct {
+	Config *config.Config
+}
+
+func (app *AppModule) Bootstrap() error {
+	// Registrar módulos aquí
+	return nil
+}
+`
+
+func TestModuleRegistration(t *testing.T) {
+	out, err := addModuleRegistration([]byte(testAppModule), "shop/src/modules/order-items", "orderitems", "OrderItem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := addModuleRegistration(out, "shop/src/modules/order-items", "orderitems", "OrderItem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(out) {
+		t.Fatalf("registration is not idempotent:\n%s", again)
+	}
+
+	for _, want := range []string{
+		`orderitems "shop/src/modules/order-items"`,
+		"\t// Registrar módulos aquí\n\torderitems.NewOrderItemModule().RegisterRoutes(app.Core.Router)\n\treturn nil",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	removed, err := removeModuleRegistration(out, "shop/src/modules/order-items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(removed) != testAppModule {
+		t.Fatalf("remove did not restore the original file:\n%s", removed)
+	}
+	if removed, _ := removeModuleRegistration(removed, "shop/src/modules/order-items"); removed != nil {
+		t.Fatal("expected nil when the module is not registered")
+	}
+}
+
+func TestModuleRegistrationNamedLikeReceiver(t *testing.T) {
+	// El módulo app coincide con el receptor de Bootstrap: app.Core no
+	// cuenta como uso del paquete y el import necesita un alias.
+	out, err := addModuleRegistration([]byte(testAppModule), "shop/src/modules/app", "app", "App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`appmodule "shop/src/modules/app"`,
+		"\tappmodule.NewAppModule().RegisterRoutes(app.Core.Router)\n\treturn nil",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// Con otro módulo ya registrado, registrar app sigue agregando su
+	// llamada.
+	withOther, err := addModuleRegistration([]byte(testAppModule), "shop/src/modules/users", "users", "Users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err = addModuleRegistration(withOther, "shop/src/modules/app", "app", "App")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "appmodule.NewAppModule()") {
+		t.Fatalf("app module not registered:\n%s", out)
+	}
+
+	removed, err := removeModuleRegistration(out, "shop/src/modules/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(removed) != string(withOther) {
+		t.Fatalf("remove did not restore the file:\n%s", removed)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type commonData struct {
+	Name     string
+	Package  string
+	FileName string
+	Key      string
+}
+
+// commonDir devuelve la carpeta donde se genera un guard o interceptor:
+// <sourceRoot>/common/<kind> por defecto o la carpeta <kind> del módulo si
+// se indica uno.
+func commonDir(kind, module string) string {
+	if module == "" {
+		return config.sourcePath("common", kind)
+	}
+	return config.modulePath(strings.Join(splitName(module), dirSeparator()), kind)
+}
+
+func newCommonData(name, suffix, pkg string) commonData {
+	words := splitName(name)
+	if len(words) > 0 && words[len(words)-1] == strings.ToLower(suffix) {
+		words = words[:len(words)-1]
+	}
+	return commonData{
+		Name:     pascalCase(words) + suffix,
+		Package:  pkg,
+		FileName: strings.Join(words, "_"),
+		Key:      strings.Join(words, "_"),
+	}
+}
+
+func generateGuard(name, module string) {
+	data := newCommonData(name, "Guard", "guards")
+	if data.FileName == "" {
+		failf("nombre de guard inválido: %q", name)
+		return
+	}
+	if generateCommon(commonDir("guards", module), data, "guard") != nil {
+		return
+	}
+	fmt.Printf("✅ Guard %s generado\n", data.Name)
+	fmt.Printf("💡 Regístralo con guards.GuardMiddleware(New%s())\n", data.Name)
+}
+
+func generateInterceptor(name, module string) {
+	data := newCommonData(name, "Interceptor", "interceptors")
+	if data.FileName == "" {
+		failf("nombre de interceptor inválido: %q", name)
+		return
+	}
+	if generateCommon(commonDir("interceptors", module), data, "interceptor") != nil {
+		return
+	}
+	fmt.Printf("✅ Interceptor %s generado\n", data.Name)
+	fmt.Printf("💡 Regístralo con guards.InterceptorMiddleware(New%s())\n", data.Name)
+}
+
+// generateCommon escribe <nombre>.<kind>.go y su test con las plantillas
+// <kind>/<kind>.go y <kind>/<kind>_test.go.
+func generateCommon(dir string, data commonData, kind string) error {
+	files := [][2]string{
+		{filepath.Join(dir, data.FileName+"."+kind+".go"), kind + "/" + kind + ".go"},
+		{filepath.Join(dir, data.FileName+"."+kind+"_test.go"), kind + "/" + kind + "_test.go"},
+	}
+	for _, file := range files {
+		if err := renderTemplate(file[0], file[1], data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateGuardAndInterceptor(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	generateGuard("AdminGuard", "")
+	generateInterceptor("request-timing", "users")
+
+	tests := []struct {
+		path string
+		name string
+	}{
+		{"src/common/guards/admin.guard.go", "AdminGuard"},
+		{"src/common/guards/admin.guard_test.go", "TestAdminGuard"},
+		{"src/modules/users/interceptors/request_timing.interceptor.go", "RequestTimingInterceptor"},
+		{"src/modules/users/interceptors/request_timing.interceptor_test.go", "TestRequestTimingInterceptor"},
+	}
+
+	for _, tt := range tests {
+		file, err := parser.ParseFile(token.NewFileSet(), tt.path, nil, parser.AllErrors)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if file.Scope.Lookup(tt.name) == nil {
+			t.Errorf("%s: %s not declared", tt.path, tt.name)
+		}
+	}
+}
+
+func TestGeneratedGuardAndInterceptorBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles the generated code")
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	useGoneyModule(t, filepath.Dir(wd))
+	useWriter(t, &fileWriter{out: io.Discard})
+	generateGuard("AdminGuard", "")
+	generateInterceptor("request-timing", "users")
+
+	goBuildGenerated(t, "./src/...")
+}
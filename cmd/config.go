@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+)
+
+// configFiles son los nombres aceptados para la configuración del proyecto,
+// que se busca en el directorio actual.
+var configFiles = []string{"goney.json", "goney.yaml", "goney.yml"}
+
+// projectConfig es la configuración que leen todos los comandos. Los campos
+// que no aparecen en el archivo conservan el valor por defecto.
+type projectConfig struct {
+	// Module sustituye al path de go.mod en los imports generados.
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
+	// SourceRoot contiene app.module.go, common y microservices.
+	SourceRoot string `json:"sourceRoot" yaml:"sourceRoot"`
+	// ModulesDir es relativo a SourceRoot.
+	ModulesDir string       `json:"modulesDir" yaml:"modulesDir"`
+	Templates  string       `json:"templates" yaml:"templates"`
+	Transports []string     `json:"transports" yaml:"transports"`
+	Defaults   flagDefaults `json:"defaults" yaml:"defaults"`
+	Naming     namingConfig `json:"naming" yaml:"naming"`
+
+	// file es el archivo del que se leyó; vacío si el proyecto no tiene.
+	file string
+	// dir es la raíz del proyecto en disco; vacío es el directorio actual.
+	// goney new lo usa para generar dentro del proyecto que acaba de crear.
+	dir string
+}
+
+// flagDefaults son los valores de --global y --flat cuando no se pasan.
+type flagDefaults struct {
+	Global bool `json:"global" yaml:"global"`
+	Flat   bool `json:"flat" yaml:"flat"`
+}
+
+type namingConfig struct {
+	// Style decide el nombre de carpetas y archivos de los módulos:
+	// kebab (order-items) o snake (order_items).
+	Style       string            `json:"style" yaml:"style"`
+	Language    string            `json:"language" yaml:"language"`
+	Irregular   map[string]string `json:"irregular,omitempty" yaml:"irregular,omitempty"`
+	Uncountable []string          `json:"uncountable,omitempty" yaml:"uncountable,omitempty"`
+}
+
+var transports = []string{"http", "grpc", "nats", "tcp"}
+
+func defaultConfig() *projectConfig {
+	return &projectConfig{
+		SourceRoot: "src",
+		ModulesDir: "modules",
+		Templates:  ".goney/templates",
+		Transports: []string{"http"},
+		Naming:     namingConfig{Style: "kebab", Language: "en"},
+	}
+}
+
+var config = defaultConfig()
+
+// loadProjectConfig lee goney.json o goney.yaml del directorio actual; sin
+// archivo devuelve la configuración por defecto.
+func loadProjectConfig() (*projectConfig, error) {
+	cfg := defaultConfig()
+	for _, name := range configFiles {
+		content, err := os.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if cfg.file != "" {
+			return nil, fmt.Errorf("hay dos archivos de configuración (%s y %s); deja solo uno", cfg.file, name)
+		}
+		if strings.HasSuffix(name, ".json") {
+			err = json.Unmarshal(content, cfg)
+		} else {
+			err = yaml.Unmarshal(content, cfg)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		cfg.file = name
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.file, err)
+	}
+	return cfg, nil
+}
+
+func (c *projectConfig) validate() error {
+	if c.SourceRoot == "" || c.ModulesDir == "" {
+		return fmt.Errorf("sourceRoot y modulesDir no pueden estar vacíos")
+	}
+	for _, dir := range []string{c.SourceRoot, c.ModulesDir} {
+		if filepath.IsAbs(dir) || strings.HasPrefix(path.Clean(filepath.ToSlash(dir)), "..") {
+			return fmt.Errorf("%q debe ser una ruta relativa dentro del proyecto", dir)
+		}
+	}
+	if c.Naming.Style != "kebab" && c.Naming.Style != "snake" {
+		return fmt.Errorf("naming.style %q no soportado (kebab, snake)", c.Naming.Style)
+	}
+	if _, ok := defaultInflections[c.Naming.Language]; !ok {
+		return fmt.Errorf("naming.language %q no soportado (en, es)", c.Naming.Language)
+	}
+	for _, t := range c.Transports {
+		if !contains(transports, t) {
+			return fmt.Errorf("transporte %q no soportado (%s)", t, strings.Join(transports, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *projectConfig) HasTransport(name string) bool {
+	return contains(c.Transports, name)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sourcePath y modulePath devuelven rutas en disco; importPath y
+// moduleImportPath, imports de Go (siempre con "/").
+func (c *projectConfig) sourcePath(elem ...string) string {
+	return filepath.Join(append([]string{c.dir, filepath.FromSlash(c.SourceRoot)}, elem...)...)
+}
+
+func (c *projectConfig) modulePath(elem ...string) string {
+	return c.sourcePath(append([]string{filepath.FromSlash(c.ModulesDir)}, elem...)...)
+}
+
+func (c *projectConfig) importPath(elem ...string) string {
+	return path.Join(append([]string{getProjectModuleName(), filepath.ToSlash(c.SourceRoot)}, elem...)...)
+}
+
+func (c *projectConfig) moduleImportPath(elem ...string) string {
+	return c.importPath(append([]string{filepath.ToSlash(c.ModulesDir)}, elem...)...)
+}
+
+func (c *projectConfig) appModulePath() string {
+	return c.sourcePath("app.module.go")
+}
+
+// boolFlag devuelve el valor del flag si se pasó y si no el de la
+// configuración.
+func boolFlag(cmd *cobra.Command, name string, fallback bool) bool {
+	if cmd.Flags().Changed(name) {
+		value, _ := cmd.Flags().GetBool(name)
+		return value
+	}
+	return fallback
+}
+
+// writeProjectConfig crea goney.json en un proyecto nuevo.
+func writeProjectConfig(dir string, cfg *projectConfig) error {
+	content, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writer.WriteFile(filepath.Join(dir, configFiles[0]), append(content, '\n'))
+}
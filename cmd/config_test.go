@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useConfig sustituye la configuración global durante el test.
+func useConfig(t *testing.T, cfg *projectConfig) {
+	t.Helper()
+	prev := config
+	config = cfg
+	t.Cleanup(func() { config = prev })
+}
+
+func TestLoadProjectConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    func(*projectConfig)
+		wantErr string
+	}{
+		{name: "sin archivo", want: func(*projectConfig) {}},
+		{
+			name:  "json parcial",
+			files: map[string]string{"goney.json": `{"modulesDir": "features", "defaults": {"flat": true}}`},
+			want: func(c *projectConfig) {
+				c.ModulesDir = "features"
+				c.Defaults.Flat = true
+				c.file = "goney.json"
+			},
+		},
+		{
+			name:  "yaml",
+			files: map[string]string{"goney.yaml": "module: example.com/shop\nsourceRoot: internal\ntransports: [http, grpc]\nnaming:\n  language: es\n"},
+			want: func(c *projectConfig) {
+				c.Module = "example.com/shop"
+				c.SourceRoot = "internal"
+				c.Transports = []string{"http", "grpc"}
+				c.Naming.Language = "es"
+				c.file = "goney.yaml"
+			},
+		},
+		{name: "dos archivos", files: map[string]string{"goney.json": "{}", "goney.yml": ""}, wantErr: "dos archivos"},
+		{name: "estilo", files: map[string]string{"goney.json": `{"naming": {"style": "camel"}}`}, wantErr: "naming.style"},
+		{name: "transporte", files: map[string]string{"goney.json": `{"transports": ["amqp"]}`}, wantErr: "amqp"},
+		{name: "fuera del proyecto", files: map[string]string{"goney.json": `{"sourceRoot": "../src"}`}, wantErr: "relativa"},
+		{name: "json inválido", files: map[string]string{"goney.json": `{`}, wantErr: "goney.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wd, _ := os.Getwd()
+			if err := os.Chdir(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(wd)
+			for name, content := range tt.files {
+				os.WriteFile(name, []byte(content), 0644)
+			}
+
+			got, err := loadProjectConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := defaultConfig()
+			tt.want(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestConfigPaths(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	cfg := defaultConfig()
+	cfg.Module = "example.com/shop"
+	cfg.SourceRoot = "internal"
+	cfg.ModulesDir = "features"
+	useConfig(t, cfg)
+
+	data, err := newCrudData("order-items", false, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.ModuleImport != "example.com/shop/internal/features/order-items" {
+		t.Errorf("module import = %q", data.ModuleImport)
+	}
+	if got := crudPath(data.Module, "dto", ".dto.go"); got != "internal/features/order-items/dto/order-items.dto.go" {
+		t.Errorf("crud path = %q", got)
+	}
+	if got := config.appModulePath(); got != "internal/app.module.go" {
+		t.Errorf("app module = %q", got)
+	}
+}
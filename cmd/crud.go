@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+)
+
+// crudData alimenta las plantillas del módulo CRUD con subcarpetas
+// (controllers, services, repositories, dto y models).
+type crudData struct {
+	Project string
+	// Module es la carpeta del módulo y ModuleImport su path de import.
+	Module       string
+	ModuleImport string
+	Package      string
+	ClassName    string
+	VarName      string
+	Route        string
+	EntityName   string
+	TableName    string
+	DTOImport    string
+	ModelImport  string
+	// Tipos calificados, p. ej. dto.UsersResponse o dto.BaseResponse en modo
+	// global.
+	ResponseType string
+	CreateType   string
+	UpdateType   string
+	ModelType    string
+	GlobalDTO    bool
+	GlobalModel  bool
+	Fields       []fieldSpec
+}
+
+func newCrudData(moduleName string, globalDTO, globalModel bool, fields []fieldSpec) (crudData, error) {
+	n, err := newNames(moduleName)
+	if err != nil {
+		return crudData{}, err
+	}
+	project := getProjectModuleName()
+	class := n.Pascal
+	base := config.moduleImportPath(n.Dir)
+
+	data := crudData{
+		Project:      project,
+		Module:       n.Dir,
+		ModuleImport: base,
+		Package:      n.Package,
+		ClassName:    class,
+		VarName:      n.Camel,
+		Route:        n.Route,
+		EntityName:   n.Human,
+		TableName:    n.Table,
+		DTOImport:    base + "/dto",
+		ModelImport:  base + "/models",
+		ResponseType: "dto." + class + "Response",
+		CreateType:   "dto.Create" + class + "Request",
+		UpdateType:   "dto.Update" + class + "Request",
+		ModelType:    "models." + class,
+		GlobalDTO:    globalDTO,
+		GlobalModel:  globalModel,
+		Fields:       fields,
+	}
+	if globalDTO {
+		data.DTOImport = config.importPath("common", "dto")
+		data.ResponseType = "dto.BaseResponse"
+		data.CreateType = "dto.BaseCreateRequest"
+		data.UpdateType = "dto.BaseUpdateRequest"
+	}
+	if globalModel {
+		data.ModelImport = config.importPath("common", "models")
+		data.ModelType = "models.Named"
+	}
+	// Los DTOs y modelos globales solo tienen Name y Description.
+	if len(fields) == 0 || globalDTO || globalModel {
+		data.Fields = defaultFields
+	}
+	return data, nil
+}
+
+// ServiceImports devuelve los imports del service en el orden de gofmt.
+func (d crudData) ServiceImports() []string {
+	imports := []string{d.DTOImport, d.ModelImport, d.ModuleImport + "/repositories"}
+	sort.Strings(imports)
+	return imports
+}
+
+func (d crudData) FilterFields() []fieldSpec {
+	var fields []fieldSpec
+	for _, field := range d.Fields {
+		if field.Filterable() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (d crudData) UniqueFields() []fieldSpec {
+	var fields []fieldSpec
+	for _, field := range d.Fields {
+		if field.Unique {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// HasRequired indica si el DTO de creación valida campos obligatorios; los
+// DTOs globales no llevan validaciones.
+func (d crudData) HasRequired() bool {
+	if d.GlobalDTO {
+		return false
+	}
+	for _, field := range d.Fields {
+		if field.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// SampleBody es un JSON válido para crear el recurso en los tests.
+func (d crudData) SampleBody() string {
+	return sampleJSON(d.Fields)
+}
+
+// SampleFilter devuelve una query que filtra por el primer campo string del
+// cuerpo de ejemplo, o "" si no hay ninguno; SampleFilterMiss filtra por el
+// mismo campo sin coincidencias.
+func (d crudData) SampleFilter() string {
+	if field, value := d.sampleFilterField(); field != nil {
+		return field.JSONName() + "=" + url.QueryEscape(value)
+	}
+	return ""
+}
+
+func (d crudData) SampleFilterMiss() string {
+	if field, _ := d.sampleFilterField(); field != nil {
+		return field.JSONName() + "=missing"
+	}
+	return ""
+}
+
+func (d crudData) sampleFilterField() (*fieldSpec, string) {
+	for _, field := range d.FilterFields() {
+		if value, ok := field.sample().(string); ok {
+			return &field, value
+		}
+	}
+	return nil, ""
+}
+
+// generateModularCRUD genera el módulo con la estructura documentada en el
+// README. Con --no-dto/--no-model (o --global) se usan los DTOs y modelos
+// compartidos de src/common y se ignora --fields.
+func generateModularCRUD(moduleName string, global, noDto, noModel bool, fields []fieldSpec) {
+	data, ok := writeModularCRUD(moduleName, global, noDto, noModel, fields)
+	if !ok {
+		return
+	}
+
+	fmt.Printf("✅ Módulo CRUD %s generado en %s/\n", data.ClassName, filepath.ToSlash(config.modulePath(data.Module)))
+	if err := registerModule(data); err != nil {
+		fmt.Printf("⚠️  No se pudo registrar el módulo en %s: %v\n", config.appModulePath(), err)
+		fmt.Printf("💡 Regístralo a mano con: %s.New%sModule().RegisterRoutes(app.Core.Router)\n", data.Package, data.ClassName)
+		return
+	}
+	fmt.Printf("🔗 Módulo registrado en %s\n", config.appModulePath())
+}
+
+// writeModularCRUD escribe los archivos del módulo sin registrarlo en
+// app.module.go.
+func writeModularCRUD(moduleName string, global, noDto, noModel bool, fields []fieldSpec) (crudData, bool) {
+	globalDTO, globalModel := global || noDto, global || noModel
+	data, err := newCrudData(moduleName, globalDTO, globalModel, fields)
+	if err != nil {
+		failf("%v", err)
+		return crudData{}, false
+	}
+	if globalDTO || globalModel {
+		if fields != nil {
+			fmt.Printf("⚠️  --fields no aplica con DTOs o modelos globales; se usan name y description\n")
+		}
+		ensureGlobalFiles()
+	}
+
+	generateModuleController(data)
+	generateModuleService(data)
+	generateModuleRepository(data)
+	if !globalDTO {
+		generateModuleDTO(data)
+	}
+	if !globalModel {
+		generateModuleModel(data)
+	}
+	generateModuleFile(data)
+	generateModuleTests(data)
+	return data, !writer.Failed()
+}
+
+func crudPath(moduleName, dir, suffix string) string {
+	return config.modulePath(moduleName, dir, moduleName+suffix)
+}
+
+func generateModuleController(data crudData) {
+	renderTemplate(crudPath(data.Module, "controllers", ".controller.go"), "crud/controller.go", data)
+}
+
+func generateModuleService(data crudData) {
+	renderTemplate(crudPath(data.Module, "services", ".service.go"), "crud/service.go", data)
+}
+
+func generateModuleRepository(data crudData) {
+	renderTemplate(crudPath(data.Module, "repositories", ".repository.go"), "crud/repository.go", data)
+}
+
+func generateModuleDTO(data crudData) {
+	renderTemplate(crudPath(data.Module, "dto", ".dto.go"), "crud/dto.go", data)
+}
+
+func generateModuleModel(data crudData) {
+	renderTemplate(crudPath(data.Module, "models", ".model.go"), "crud/model.go", data)
+}
+
+func generateModuleFile(data crudData) {
+	renderTemplate(crudPath(data.Module, "", ".module.go"), "crud/module.go", data)
+}
+
+func generateModuleTests(data crudData) {
+	renderTemplate(crudPath(data.Module, "", "_test.go"), "crud/module_test.go", data)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+)
+
+func TestGenerateModuleCRUD(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.23\n"), 0644)
+
+	fields, err := parseFields("name:string:required price:float64:min=0 sku:string:unique tags:[]string owner:ref:user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generateModuleCRUD("products", false, false, false, false, fields)
+	generateModuleCRUD("orders", false, false, true, false, nil)
+	generateModuleCRUD("tags", false, false, false, true, nil)
+
+	tests := []struct {
+		path   string
+		exists bool
+	}{
+		{"src/modules/products/products.module.go", true},
+		{"src/modules/products/products_test.go", true},
+		{"src/modules/products/controllers/products.controller.go", true},
+		{"src/modules/products/services/products.service.go", true},
+		{"src/modules/products/repositories/products.repository.go", true},
+		{"src/modules/products/dto/products.dto.go", true},
+		{"src/modules/products/models/products.model.go", true},
+		{"src/modules/orders/dto/orders.dto.go", true},
+		{"src/modules/orders/models/orders.model.go", false},
+		{"src/common/models/base.go", true},
+		{"src/modules/tags/tags.go", true},
+		{"src/modules/tags/controllers/tags.controller.go", false},
+	}
+
+	for _, tt := range tests {
+		_, err := os.Stat(tt.path)
+		if exists := err == nil; exists != tt.exists {
+			t.Errorf("%s: exists = %v, want %v", tt.path, exists, tt.exists)
+			continue
+		}
+		if tt.exists {
+			if _, err := parser.ParseFile(token.NewFileSet(), tt.path, nil, parser.AllErrors); err != nil {
+				t.Errorf("%s: %v", tt.path, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+)
+
+// fieldSpec describe un campo del modelo generado. Se obtiene de --fields
+// ("price:float64:min=0") o de un archivo YAML con --fields-file.
+type fieldSpec struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"`
+	Ref      string   `yaml:"ref"`
+	Required bool     `yaml:"required"`
+	Unique   bool     `yaml:"unique"`
+	Email    bool     `yaml:"email"`
+	Min      *float64 `yaml:"min"`
+	Max      *float64 `yaml:"max"`
+}
+
+var fieldTypes = map[string]string{
+	"string":  "string",
+	"int":     "int",
+	"int32":   "int32",
+	"int64":   "int64",
+	"uint":    "uint",
+	"float32": "float32",
+	"float64": "float64",
+	"bool":    "bool",
+	"time":    "time.Time",
+}
+
+// defaultFields son los campos que se generan si no se indica --fields.
+var defaultFields = []fieldSpec{
+	{Name: "name", Type: "string", Required: true},
+	{Name: "description", Type: "string"},
+}
+
+var reservedFields = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+// fieldsFromFlags lee --fields y --fields-file; sin ninguno de los dos
+// devuelve nil.
+func fieldsFromFlags(cmd *cobra.Command) ([]fieldSpec, error) {
+	spec, _ := cmd.Flags().GetString("fields")
+	file, _ := cmd.Flags().GetString("fields-file")
+	switch {
+	case spec != "" && file != "":
+		return nil, fmt.Errorf("usa --fields o --fields-file, no ambos")
+	case spec != "":
+		return parseFields(spec)
+	case file != "":
+		return loadFieldsFile(file)
+	}
+	return nil, nil
+}
+
+// parseFields interpreta "nombre:tipo[:modificador...]" separados por
+// espacios o comas. Los modificadores son required, unique, email, min=N y
+// max=N; el tipo ref lleva la entidad referenciada ("owner:ref:user").
+func parseFields(spec string) ([]fieldSpec, error) {
+	var fields []fieldSpec
+	for _, item := range strings.FieldsFunc(spec, func(r rune) bool { return r == ' ' || r == ',' }) {
+		parts := strings.Split(item, ":")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("campo %q: se esperaba nombre:tipo", item)
+		}
+		field := fieldSpec{Name: parts[0], Type: parts[1]}
+		mods := parts[2:]
+		if field.Type == "ref" && len(mods) > 0 {
+			field.Ref, mods = mods[0], mods[1:]
+		}
+		for _, mod := range mods {
+			if err := field.applyModifier(mod); err != nil {
+				return nil, fmt.Errorf("campo %q: %w", field.Name, err)
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, validateFields(fields)
+}
+
+func loadFieldsFile(path string) ([]fieldSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec struct {
+		Fields []fieldSpec `yaml:"fields"`
+	}
+	if err := yaml.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec.Fields, validateFields(spec.Fields)
+}
+
+func (f *fieldSpec) applyModifier(mod string) error {
+	key, value, hasValue := strings.Cut(mod, "=")
+	switch {
+	case key == "required" && !hasValue:
+		f.Required = true
+	case key == "unique" && !hasValue:
+		f.Unique = true
+	case key == "email" && !hasValue:
+		f.Email = true
+	case (key == "min" || key == "max") && hasValue:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s inválido: %q", key, value)
+		}
+		if key == "min" {
+			f.Min = &n
+		} else {
+			f.Max = &n
+		}
+	default:
+		return fmt.Errorf("modificador desconocido %q", mod)
+	}
+	return nil
+}
+
+func validateFields(fields []fieldSpec) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("no se indicó ningún campo")
+	}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		words := splitName(field.Name)
+		if len(words) == 0 {
+			return fmt.Errorf("campo sin nombre")
+		}
+		if reservedFields[field.JSONName()] {
+			return fmt.Errorf("campo %q: nombre reservado", field.Name)
+		}
+		if seen[field.JSONName()] {
+			return fmt.Errorf("campo %q duplicado", field.Name)
+		}
+		seen[field.JSONName()] = true
+
+		base := strings.TrimPrefix(field.Type, "[]")
+		switch {
+		case field.Type == "ref":
+			if field.Ref == "" {
+				return fmt.Errorf("campo %q: ref necesita la entidad (owner:ref:user)", field.Name)
+			}
+		case fieldTypes[base] == "":
+			return fmt.Errorf("campo %q: tipo no soportado %q", field.Name, field.Type)
+		}
+		if field.Email && field.GoType() != "string" {
+			return fmt.Errorf("campo %q: email solo aplica a string", field.Name)
+		}
+		if field.Unique && field.IsSlice() {
+			return fmt.Errorf("campo %q: unique no aplica a listas", field.Name)
+		}
+	}
+	return nil
+}
+
+func (f fieldSpec) GoName() string {
+	name := pascalCase(splitName(f.Name))
+	if f.Type == "ref" {
+		name += "ID"
+	}
+	return name
+}
+
+func (f fieldSpec) JSONName() string {
+	name := strings.Join(splitName(f.Name), "_")
+	if f.Type == "ref" {
+		name += "_id"
+	}
+	return name
+}
+
+func (f fieldSpec) GoType() string {
+	if f.Type == "ref" {
+		return "string"
+	}
+	if base, ok := strings.CutPrefix(f.Type, "[]"); ok {
+		return "[]" + fieldTypes[base]
+	}
+	return fieldTypes[f.Type]
+}
+
+func (f fieldSpec) IsSlice() bool {
+	return strings.HasPrefix(f.Type, "[]")
+}
+
+// isInteger indica si el campo (o sus elementos) es de un tipo entero.
+func (f fieldSpec) isInteger() bool {
+	switch strings.TrimPrefix(f.GoType(), "[]") {
+	case "int", "int32", "int64", "uint":
+		return true
+	}
+	return false
+}
+
+// hasZeroValue indica si el valor cero del campo es un valor válido que
+// binding:"required" rechazaría (0, false).
+func (f fieldSpec) hasZeroValue() bool {
+	return !f.IsSlice() && (f.isInteger() || f.GoType() == "float32" || f.GoType() == "float64" || f.GoType() == "bool")
+}
+
+// Filterable indica si el campo se puede usar como filtro por igualdad en
+// la query string.
+func (f fieldSpec) Filterable() bool {
+	return !f.IsSlice() && f.GoType() != "time.Time"
+}
+
+func (f fieldSpec) ModelTag() string {
+	tag := fmt.Sprintf("json:%q", f.JSONName())
+	switch {
+	case f.Unique:
+		tag += ` gorm:"uniqueIndex"`
+	case f.Type == "ref":
+		tag += ` gorm:"index"`
+	case f.IsSlice():
+		// gorm no sabe mapear listas a una columna; se guardan como JSON.
+		tag += ` gorm:"serializer:json"`
+	}
+	return "`" + tag + "`"
+}
+
+// CreateTag valida los campos opcionales solo si se envían, como UpdateTag.
+// Los números y bool obligatorios no llevan required, que rechazaría 0 y
+// false.
+func (f fieldSpec) CreateTag() string {
+	rules := f.rules()
+	switch {
+	case f.Required && f.hasZeroValue():
+	case f.Required:
+		rules = append([]string{"required"}, rules...)
+	case len(rules) > 0:
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return f.tag(f.JSONName(), rules)
+}
+
+func (f fieldSpec) UpdateTag() string {
+	rules := f.rules()
+	if len(rules) > 0 {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return f.tag(f.JSONName()+",omitempty", rules)
+}
+
+// NonZero es la condición Go que indica que expr (un valor del campo) no es
+// el valor cero.
+func (f fieldSpec) NonZero(expr string) string {
+	switch base := f.GoType(); {
+	case f.IsSlice():
+		return "len(" + expr + ") > 0"
+	case base == "string":
+		return expr + ` != ""`
+	case base == "bool":
+		return expr
+	case base == "time.Time":
+		return "!" + expr + ".IsZero()"
+	}
+	return expr + " != 0"
+}
+
+// UpdateType usa punteros para distinguir "no enviado" del valor cero.
+func (f fieldSpec) UpdateType() string {
+	if f.IsSlice() {
+		return f.GoType()
+	}
+	return "*" + f.GoType()
+}
+
+func (f fieldSpec) rules() []string {
+	var rules []string
+	if f.Email {
+		rules = append(rules, "email")
+	}
+	if f.Min != nil {
+		rules = append(rules, "min="+strconv.FormatFloat(*f.Min, 'f', -1, 64))
+	}
+	if f.Max != nil {
+		rules = append(rules, "max="+strconv.FormatFloat(*f.Max, 'f', -1, 64))
+	}
+	return rules
+}
+
+func (f fieldSpec) tag(name string, rules []string) string {
+	tag := fmt.Sprintf("json:%q", name)
+	if len(rules) > 0 {
+		tag += fmt.Sprintf(" binding:%q", strings.Join(rules, ","))
+	}
+	return "`" + tag + "`"
+}
+
+// sample devuelve un valor válido para el campo, usado por los tests
+// generados.
+func (f fieldSpec) sample() interface{} {
+	base := strings.TrimPrefix(f.GoType(), "[]")
+	var value interface{}
+	switch {
+	case f.Email:
+		value = "user@example.com"
+	case base == "string" && f.Type == "ref":
+		value = "1"
+	case base == "string":
+		text := "sample-" + strings.Join(splitName(f.Name), "-")
+		if f.Max != nil && float64(len(text)) > *f.Max {
+			text = strings.Repeat("a", int(*f.Max))
+		}
+		if f.Min != nil && float64(len(text)) < *f.Min {
+			text = strings.Repeat("a", int(math.Ceil(*f.Min)))
+		}
+		value = text
+	case base == "bool":
+		value = true
+	case base == "time.Time":
+		value = "2024-01-02T15:04:05Z"
+	default:
+		n := 1.0
+		if f.Min != nil {
+			n = *f.Min
+		} else if f.Max != nil && *f.Max < n {
+			n = *f.Max
+		}
+		if f.isInteger() {
+			// Un entero no admite decimales: min=0.5 pide al menos 1.
+			if f.Min != nil {
+				n = math.Ceil(n)
+			} else {
+				n = math.Floor(n)
+			}
+		}
+		value = n
+	}
+	if f.IsSlice() {
+		return []interface{}{value}
+	}
+	return value
+}
+
+func sampleJSON(fields []fieldSpec) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value, _ := json.Marshal(field.sample())
+		parts = append(parts, fmt.Sprintf("%q:%s", field.JSONName(), value))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
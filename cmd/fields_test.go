@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields("name:string:required price:float64:min=0 sku:string:unique tags:[]string owner:ref:user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		goName, goType, createTag, updateType string
+	}{
+		{"Name", "string", "`json:\"name\" binding:\"required\"`", "*string"},
+		{"Price", "float64", "`json:\"price\" binding:\"omitempty,min=0\"`", "*float64"},
+		{"Sku", "string", "`json:\"sku\"`", "*string"},
+		{"Tags", "[]string", "`json:\"tags\"`", "[]string"},
+		{"OwnerID", "string", "`json:\"owner_id\"`", "*string"},
+	}
+	for i, tt := range tests {
+		f := fields[i]
+		if f.GoName() != tt.goName || f.GoType() != tt.goType || f.CreateTag() != tt.createTag || f.UpdateType() != tt.updateType {
+			t.Errorf("field %d = %s %s %s %s, want %+v", i, f.GoName(), f.GoType(), f.CreateTag(), f.UpdateType(), tt)
+		}
+	}
+	if got := fields[1].UpdateTag(); got != "`json:\"price,omitempty\" binding:\"omitempty,min=0\"`" {
+		t.Errorf("price update tag = %s", got)
+	}
+}
+
+func TestParseFieldsErrors(t *testing.T) {
+	for _, spec := range []string{"name", "name:weird", "id:string", "a:string b:int a:bool", "owner:ref", "n:int:email", "n:int:min=x", "n:int:nope", "tags:[]string:unique"} {
+		if _, err := parseFields(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestLoadFieldsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fields.yaml")
+	os.WriteFile(path, []byte("fields:\n  - name: email\n    type: string\n    email: true\n    unique: true\n  - name: stock\n    type: int\n    min: 0\n"), 0644)
+
+	fields, err := loadFieldsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 || !fields[0].Unique || fields[1].CreateTag() != "`json:\"stock\" binding:\"omitempty,min=0\"`" {
+		t.Fatalf("unexpected fields: %+v", fields)
+	}
+}
+
+func TestCreateTagSkipsRulesForEmptyOptionalFields(t *testing.T) {
+	fields, err := parseFields("email:string:email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Se enlaza un struct con el tag generado, como hace el controlador.
+	dto := reflect.StructOf([]reflect.StructField{{
+		Name: fields[0].GoName(),
+		Type: reflect.TypeOf(""),
+		Tag:  reflect.StructTag(strings.Trim(fields[0].CreateTag(), "`")),
+	}})
+
+	if err := binding.JSON.BindBody([]byte(`{}`), reflect.New(dto).Interface()); err != nil {
+		t.Errorf("empty optional email: %v", err)
+	}
+	if err := binding.JSON.BindBody([]byte(`{"email":"not-an-email"}`), reflect.New(dto).Interface()); err == nil {
+		t.Error("invalid email should fail")
+	}
+}
+
+func TestCreateTagAcceptsZeroForRequiredScalars(t *testing.T) {
+	fields, err := parseFields("stock:int:required active:bool:required price:float64:required:min=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dto := reflect.StructOf([]reflect.StructField{
+		{Name: "Stock", Type: reflect.TypeOf(0), Tag: reflect.StructTag(strings.Trim(fields[0].CreateTag(), "`"))},
+		{Name: "Active", Type: reflect.TypeOf(false), Tag: reflect.StructTag(strings.Trim(fields[1].CreateTag(), "`"))},
+		{Name: "Price", Type: reflect.TypeOf(0.0), Tag: reflect.StructTag(strings.Trim(fields[2].CreateTag(), "`"))},
+	})
+	if err := binding.JSON.BindBody([]byte(`{"stock":0,"active":false,"price":0}`), reflect.New(dto).Interface()); err != nil {
+		t.Errorf("zero values of required scalars: %v", err)
+	}
+	if err := binding.JSON.BindBody([]byte(`{"price":-1}`), reflect.New(dto).Interface()); err == nil {
+		t.Error("price below min should fail")
+	}
+}
+
+func TestModelTagSerializesSlices(t *testing.T) {
+	fields, err := parseFields("tags:[]string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fields[0].ModelTag(); got != "`json:\"tags\" gorm:\"serializer:json\"`" {
+		t.Errorf("model tag = %s", got)
+	}
+}
+
+func TestSampleRoundsIntegerBounds(t *testing.T) {
+	fields, err := parseFields("stock:int:min=0.5 count:int64:max=0.5 ratio:float64:min=0.5 code:string:min=12.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{1.0, 0.0, 0.5, strings.Repeat("a", 13)}
+	for i, field := range fields {
+		if got := field.sample(); got != want[i] {
+			t.Errorf("%s: sample = %v, want %v", field.Name, got, want[i])
+		}
+	}
+}
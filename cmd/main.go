@@ -9,7 +9,7 @@ import (
 
 var rootCmd = &cobra.Command{
 	Use:     "goney",
-	Version: "1.0.4",
+	Version: "1.1.0",
 	Short:   "Go-ney - Framework MVC para Go inspirado en NestJS",
 	Long: `Go-ney es un framework CLI inspirado en NestJS para crear aplicaciones Go
 con arquitectura MVC modular y soporte para microservicios TCP, NAT y gRPC.`,
@@ -23,11 +23,17 @@ var resourceCmd = &cobra.Command{
     Args:    cobra.ExactArgs(1),
     Run: func(cmd *cobra.Command, args []string) {
         moduleName := args[0]
-        global, _ := cmd.Flags().GetBool("global")
+        global := boolFlag(cmd, "global", config.Defaults.Global)
         noDto, _ := cmd.Flags().GetBool("no-dto")
         noModel, _ := cmd.Flags().GetBool("no-model")
+        flat := boolFlag(cmd, "flat", config.Defaults.Flat)
+        fields, err := fieldsFromFlags(cmd)
+        if err != nil {
+            failf("%v", err)
+            return
+        }
         fmt.Printf("Generando recurso (CRUD): %s\n", moduleName)
-        generateModule(moduleName, true, global, noDto, noModel)
+        generateModuleCRUD(moduleName, global, noDto, noModel, flat, fields)
     },
 }
 
@@ -58,13 +64,25 @@ var overrideModulePath string
 var newCmd = &cobra.Command{
 	Use:   "new [nombre-proyecto]",
 	Short: "Crear un nuevo proyecto Go-ney",
-	Args:  cobra.ExactArgs(1),
+	Long: `Crear un nuevo proyecto Go-ney. Las opciones que no se pasen se preguntan
+en la terminal (con --yes se usan los valores por defecto).
+
+Ejemplos:
+  goney new api                                        # Asistente
+  goney new api --db postgres --transport http --preset minimal
+  goney new api --db none --transport http,grpc,nats --preset full -y`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		projectName := args[0]
 		mpath, _ := cmd.Flags().GetString("module")
 		overrideModulePath = mpath
+		opts, err := projectOptionsFromFlags(cmd)
+		if err != nil {
+			failf("%v", err)
+			return
+		}
 		fmt.Printf("Creando proyecto Go-ney: %s\n", projectName)
-		createNewProject(projectName)
+		createNewProject(projectName, opts)
 	},
 }
 
@@ -125,8 +143,9 @@ var guardCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
+		module, _ := cmd.Flags().GetString("module")
 		fmt.Printf("Generando guard: %s\n", name)
-		generateGuard(name)
+		generateGuard(name, module)
 	},
 }
 
@@ -136,8 +155,9 @@ var interceptorCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
+		module, _ := cmd.Flags().GetString("module")
 		fmt.Printf("Generando interceptor: %s\n", name)
-		generateInterceptor(name)
+		generateInterceptor(name, module)
 	},
 }
 
@@ -156,12 +176,22 @@ Ejemplos:
 	Run: func(cmd *cobra.Command, args []string) {
 		moduleName := args[0]
 		crud, _ := cmd.Flags().GetBool("crud")
-		global, _ := cmd.Flags().GetBool("global")
+		global := boolFlag(cmd, "global", config.Defaults.Global)
 		noDto, _ := cmd.Flags().GetBool("no-dto")
 		noModel, _ := cmd.Flags().GetBool("no-model")
+		flat := boolFlag(cmd, "flat", config.Defaults.Flat)
 
 		fmt.Printf("Generando módulo: %s\n", moduleName)
-		generateModule(moduleName, crud, global, noDto, noModel)
+		if crud {
+			fields, err := fieldsFromFlags(cmd)
+			if err != nil {
+				failf("%v", err)
+				return
+			}
+			generateModuleCRUD(moduleName, global, noDto, noModel, flat, fields)
+			return
+		}
+		generateModule(moduleName, false, global, noDto, noModel)
 	},
 }
 
@@ -174,16 +204,25 @@ Ejemplos:
   goney generate crud users                     # Módulo users/ con toda la estructura + tests
   goney generate crud products --global        # Módulo con DTOs y modelos globales
   goney generate crud orders --no-dto          # Módulo sin generar DTO
-  goney generate crud clients --no-model       # Módulo sin generar modelo`,
+  goney generate crud clients --no-model       # Módulo sin generar modelo
+  goney generate crud tags --flat              # Módulo en un único archivo
+  goney generate crud products --fields "name:string:required price:float64:min=0 sku:string:unique"
+  goney generate crud products --fields-file product.yaml`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		moduleName := args[0]
-		global, _ := cmd.Flags().GetBool("global")
+		global := boolFlag(cmd, "global", config.Defaults.Global)
 		noDto, _ := cmd.Flags().GetBool("no-dto")
 		noModel, _ := cmd.Flags().GetBool("no-model")
+		flat := boolFlag(cmd, "flat", config.Defaults.Flat)
+		fields, err := fieldsFromFlags(cmd)
+		if err != nil {
+			failf("%v", err)
+			return
+		}
 
 		fmt.Printf("Generando módulo CRUD: %s\n", moduleName)
-		generateModuleCRUD(moduleName, global, noDto, noModel)
+		generateModuleCRUD(moduleName, global, noDto, noModel, flat, fields)
 	},
 }
 
@@ -196,24 +235,99 @@ var startCmd = &cobra.Command{
 	},
 }
 
+var removeCmd = &cobra.Command{
+	Use:     "remove",
+	Aliases: []string{"rm"},
+	Short:   "Quitar componentes generados",
+}
+
+var removeModuleCmd = &cobra.Command{
+	Use:   "module [nombre-modulo]",
+	Short: "Quitar el registro de un módulo en src/app.module.go",
+	Long: `Quitar el import y el registro de rutas de un módulo en src/app.module.go.
+
+Ejemplos:
+  goney remove module users                  # Solo quita el registro
+  goney remove module users --delete-files   # Además borra src/modules/users/`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		deleteFiles, _ := cmd.Flags().GetBool("delete-files")
+		removeModule(args[0], deleteFiles)
+	},
+}
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Gestionar las plantillas de los generadores",
+	Long: `Los generadores usan las plantillas incluidas en goney salvo que el
+proyecto tenga su propia versión en .goney/templates/<nombre>.tmpl.`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Listar las plantillas disponibles",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		listTemplates()
+	},
+}
+
+var templatesEjectCmd = &cobra.Command{
+	Use:   "eject [nombre]",
+	Short: "Copiar una plantilla a .goney/templates para personalizarla",
+	Long: `Copiar una plantilla (o todas las de una carpeta) a .goney/templates.
+
+Ejemplos:
+  goney templates eject crud/controller.go   # Solo el controller del CRUD
+  goney templates eject crud                 # Todas las plantillas del CRUD`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ejectTemplates(args[0])
+	},
+}
+
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&writer.DryRun, "dry-run", false, "Mostrar los archivos que se generarían sin escribir nada")
+	rootCmd.PersistentFlags().BoolVar(&writer.Force, "force", false, "Sobrescribir archivos existentes con contenido distinto")
+
     // Flags para 'new'
     newCmd.Flags().String("module", "", "Path del módulo para go.mod (ej: github.com/mi-org/mi-api)")
+	newCmd.Flags().String("db", "postgres", "Base de datos: postgres, mysql, sqlite o none")
+	newCmd.Flags().StringSlice("transport", []string{"http"}, "Transportes: http, grpc, nats, tcp (ej: http,grpc)")
+	newCmd.Flags().String("preset", "minimal", "minimal (solo la estructura) o full (carpetas comunes y módulo de ejemplo)")
+	newCmd.Flags().BoolP("yes", "y", false, "No preguntar; usar los valores por defecto de los flags omitidos")
 	// Flags para el comando module
 	moduleCmd.Flags().Bool("crud", false, "Generar módulo con CRUD completo")
 	moduleCmd.Flags().Bool("global", false, "Usar DTOs y modelos globales (no genera archivos específicos)")
 	moduleCmd.Flags().Bool("no-dto", false, "No generar DTO específico")
 	moduleCmd.Flags().Bool("no-model", false, "No generar modelo específico")
+	moduleCmd.Flags().String("fields", "", "Campos del modelo: \"nombre:tipo[:required|unique|email|min=N|max=N]\" (ref: owner:ref:user)")
+	moduleCmd.Flags().String("fields-file", "", "Archivo YAML con la lista de campos (clave fields)")
+	moduleCmd.Flags().Bool("flat", false, "Con --crud, generar el módulo en un único archivo")
 
 	// Flags para el comando CRUD
 	crudCmd.Flags().Bool("global", false, "Usar DTOs y modelos globales (no genera archivos específicos)")
 	crudCmd.Flags().Bool("no-dto", false, "No generar DTO específico")
 	crudCmd.Flags().Bool("no-model", false, "No generar modelo específico")
+	crudCmd.Flags().String("fields", "", "Campos del modelo: \"nombre:tipo[:required|unique|email|min=N|max=N]\" (ref: owner:ref:user)")
+	crudCmd.Flags().String("fields-file", "", "Archivo YAML con la lista de campos (clave fields)")
+	crudCmd.Flags().Bool("flat", false, "Generar el módulo en un único archivo sin subcarpetas")
 
     // Flags para 'resource' (mismos que module/crud)
     resourceCmd.Flags().Bool("global", false, "Usar DTOs y modelos globales (no genera archivos específicos)")
     resourceCmd.Flags().Bool("no-dto", false, "No generar DTO específico")
     resourceCmd.Flags().Bool("no-model", false, "No generar modelo específico")
+    resourceCmd.Flags().String("fields", "", "Campos del modelo: \"nombre:tipo[:required|unique|email|min=N|max=N]\" (ref: owner:ref:user)")
+    resourceCmd.Flags().String("fields-file", "", "Archivo YAML con la lista de campos (clave fields)")
+    resourceCmd.Flags().Bool("flat", false, "Generar el módulo en un único archivo sin subcarpetas")
+
+	// Flags para 'remove module'
+	removeModuleCmd.Flags().Bool("delete-files", false, "Borrar también la carpeta src/modules/<nombre>")
+
+	// Flags para guards e interceptores
+	guardCmd.Flags().String("module", "", "Generar el guard dentro de src/modules/<module>/guards")
+	interceptorCmd.Flags().String("module", "", "Generar el interceptor dentro de src/modules/<module>/interceptors")
 
 	generateCmd.AddCommand(moduleCmd)
 	generateCmd.AddCommand(controllerCmd)
@@ -229,12 +343,29 @@ func init() {
 
 	rootCmd.AddCommand(newCmd)
 	rootCmd.AddCommand(generateCmd)
+	removeCmd.AddCommand(removeModuleCmd)
+
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(removeCmd)
+
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesEjectCmd)
+	rootCmd.AddCommand(templatesCmd)
 }
 
 func main() {
+	cfg, err := loadProjectConfig()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	config = cfg
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	if writer.Failed() {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// goneyModule es el módulo que importan los microservicios generados.
+const goneyModule = "github.com/Go-Ney/goney"
+
+type microserviceData struct {
+	ModulePath string
+	ImportPath string
+	// Dir es la carpeta del servicio, con "/".
+	Dir       string
+	Package   string
+	Name      string
+	Subject   string
+	EnvPrefix string
+	Port      string
+}
+
+func newMicroserviceData(name string) (microserviceData, error) {
+	n, err := newNames(name)
+	if err != nil {
+		return microserviceData{}, err
+	}
+	class := n.Pascal
+	if !strings.HasSuffix(class, "Service") {
+		class += "Service"
+	}
+
+	return microserviceData{
+		ModulePath: getProjectModuleName(),
+		ImportPath: config.importPath("microservices", n.Package),
+		Dir:        filepath.ToSlash(config.sourcePath("microservices", n.Package)),
+		Package:    n.Package,
+		Name:       class,
+		Subject:    n.Kebab,
+		EnvPrefix:  strings.ToUpper(n.Snake),
+	}, nil
+}
+
+func generateMicroservice(serviceType, name string) {
+	var files [][2]string
+	var env [][2]string
+
+	data, err := newMicroserviceData(name)
+	if err != nil {
+		failf("%v", err)
+		return
+	}
+	switch serviceType {
+	case "tcp":
+		data.Port = nextFreePort(data.EnvPrefix+"_TCP_PORT", 9000)
+		files = [][2]string{
+			{"{{.Dir}}/{{.Package}}.go", "microservice/tcp/service.go"},
+			{"{{.Dir}}/{{.Package}}_test.go", "microservice/tcp/service_test.go"},
+			{"cmd/{{.Package}}/main.go", "microservice/tcp/main.go"},
+		}
+		env = [][2]string{{data.EnvPrefix + "_TCP_PORT", data.Port}}
+	case "nats":
+		files = [][2]string{
+			{"{{.Dir}}/{{.Package}}.go", "microservice/nats/service.go"},
+			{"{{.Dir}}/{{.Package}}_test.go", "microservice/nats/service_test.go"},
+			{"cmd/{{.Package}}/main.go", "microservice/nats/main.go"},
+		}
+		env = [][2]string{{data.EnvPrefix + "_NATS_QUEUE", data.Subject}}
+	case "grpc":
+		data.Port = nextFreePort(data.EnvPrefix+"_GRPC_PORT", 50051)
+		files = [][2]string{
+			{"{{.Dir}}/pb/{{.Package}}.proto", "microservice/grpc/service.proto"},
+			{"{{.Dir}}/{{.Package}}.go", "microservice/grpc/service.go"},
+			{"{{.Dir}}/{{.Package}}_test.go", "microservice/grpc/service_test.go"},
+			{"cmd/{{.Package}}/main.go", "microservice/grpc/main.go"},
+		}
+		env = [][2]string{{data.EnvPrefix + "_GRPC_PORT", data.Port}}
+	default:
+		failf("tipo de microservicio no soportado: %s (usa tcp, nats o grpc)", serviceType)
+		return
+	}
+
+	for _, file := range files {
+		path := filepath.FromSlash(renderString(file[0], data))
+		if err := renderTemplate(path, file[1], data); err != nil {
+			return
+		}
+	}
+	appendEnvEntries(env)
+	addGoneyRequire()
+
+	fmt.Printf("✅ Microservicio %s %s generado en %s/\n", serviceType, data.Name, data.Dir)
+	if serviceType == "grpc" {
+		fmt.Printf("💡 Genera el código de protobuf con: go generate ./%s/\n", data.Dir)
+	}
+	if config.file != "" && !config.HasTransport(serviceType) {
+		fmt.Printf("💡 Añade \"%s\" a transports en %s\n", serviceType, config.file)
+	}
+	fmt.Printf("🚀 Para iniciarlo: go run ./cmd/%s\n", data.Package)
+}
+
+// appendEnvEntries agrega a .env y .env.example las variables que aún no
+// están definidas.
+func appendEnvEntries(entries [][2]string) {
+	for _, envFile := range []string{".env", ".env.example"} {
+		content, err := os.ReadFile(envFile)
+		if err != nil {
+			continue
+		}
+
+		var missing []string
+		for _, entry := range entries {
+			if !strings.Contains("\n"+string(content), "\n"+entry[0]+"=") {
+				missing = append(missing, entry[0]+"="+entry[1])
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+			content = append(content, '\n')
+		}
+		content = append(content, strings.Join(missing, "\n")+"\n"...)
+		writer.UpdateFile(envFile, content)
+	}
+}
+
+var envPort = regexp.MustCompile(`(?m)^\s*([A-Z0-9_]*PORT)\s*=\s*(\d+)\s*$`)
+
+// nextFreePort devuelve el puerto de key si ya está en .env y, si no, el
+// primero desde start que no usen las demás variables *_PORT de .env y
+// .env.example ni los transportes de la aplicación.
+func nextFreePort(key string, start int) string {
+	used := make(map[int]bool)
+	for name, port := range map[string]int{"http": 8080, "grpc": 50051, "tcp": 9000} {
+		if config.HasTransport(name) {
+			used[port] = true
+		}
+	}
+	for _, envFile := range []string{".env", ".env.example"} {
+		content, err := os.ReadFile(envFile)
+		if err != nil {
+			continue
+		}
+		for _, match := range envPort.FindAllStringSubmatch(string(content), -1) {
+			if match[1] == key {
+				return match[2]
+			}
+			port, _ := strconv.Atoi(match[2])
+			used[port] = true
+		}
+	}
+
+	port := start
+	for used[port] {
+		port++
+	}
+	return strconv.Itoa(port)
+}
+
+var goneyRequire = regexp.MustCompile(`(?m)^\s*(require\s+)?` + regexp.QuoteMeta(goneyModule) + `\s`)
+
+// addGoneyRequire agrega a go.mod el módulo de goney, del que dependen los
+// microservicios, con la versión de la CLI.
+func addGoneyRequire() {
+	content, err := os.ReadFile("go.mod")
+	if err != nil || getProjectModuleName() == goneyModule || goneyRequire.Match(content) {
+		return
+	}
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, fmt.Sprintf("\nrequire %s v%s\n", goneyModule, rootCmd.Version)...)
+	writer.UpdateFile("go.mod", content)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useGoneyModule crea en el directorio actual un go.mod que resuelve goney
+// con este repositorio, para compilar el código generado sin red.
+func useGoneyModule(t *testing.T, repo string) {
+	t.Helper()
+
+	gomod := "module example.com/shop\n\ngo 1.23\n\nreplace " + goneyModule + " => " + repo + "\n"
+	if err := os.WriteFile("go.mod", []byte(gomod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(repo, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile("go.sum", sum, 0644)
+}
+
+// goBuildGenerated ejecuta go build y go vet sobre los paquetes indicados.
+func goBuildGenerated(t *testing.T, packages ...string) {
+	t.Helper()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	for _, command := range []string{"build", "vet"} {
+		cmd := exec.Command("go", append([]string{command}, packages...)...)
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %v\n%s", command, err, out)
+		}
+	}
+}
+
+func TestGenerateMicroserviceWritesValidGo(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.23\n"), 0644)
+	os.WriteFile(".env", []byte("PORT=8080"), 0644)
+
+	// Regenerar el mismo servicio con otro transporte pisa sus archivos.
+	useWriter(t, &fileWriter{Force: true, out: io.Discard})
+	for _, kind := range []string{"tcp", "nats", "grpc"} {
+		generateMicroservice(kind, "order-events")
+	}
+	generateMicroservice("tcp", "order-events")
+
+	var goFiles int
+	filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		goFiles++
+		if _, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.AllErrors); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+		return nil
+	})
+	if goFiles == 0 {
+		t.Fatal("no files generated")
+	}
+
+	if _, err := os.Stat("src/microservices/orderevents/pb/orderevents.proto"); err != nil {
+		t.Fatalf("proto not generated: %v", err)
+	}
+
+	env, _ := os.ReadFile(".env")
+	if strings.Count(string(env), "ORDER_EVENTS_TCP_PORT=9000") != 1 {
+		t.Fatalf("expected a single tcp port entry, got:\n%s", env)
+	}
+	if !strings.Contains(string(env), "ORDER_EVENTS_NATS_QUEUE=order-events") || !strings.Contains(string(env), "ORDER_EVENTS_GRPC_PORT=50051") {
+		t.Fatalf("missing config entries:\n%s", env)
+	}
+}
+
+func TestGenerateMicroserviceBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles the generated code")
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	useGoneyModule(t, filepath.Dir(wd))
+	os.WriteFile(".env", []byte("PORT=8080\nGRPC_PORT=50051\nTCP_PORT=9000\n"), 0644)
+	useWriter(t, &fileWriter{out: io.Discard})
+	generateMicroservice("tcp", "billing")
+	generateMicroservice("tcp", "shipping")
+	generateMicroservice("nats", "notifications")
+
+	gomod, _ := os.ReadFile("go.mod")
+	if !strings.Contains(string(gomod), "require "+goneyModule+" v") {
+		t.Fatalf("go.mod does not require goney:\n%s", gomod)
+	}
+	// Cada servicio toma el siguiente puerto libre, sin pisar los de la
+	// aplicación ni los de otros servicios.
+	env, _ := os.ReadFile(".env")
+	if !strings.Contains(string(env), "BILLING_TCP_PORT=9001") || !strings.Contains(string(env), "SHIPPING_TCP_PORT=9002") {
+		t.Fatalf("unexpected ports:\n%s", env)
+	}
+
+	goBuildGenerated(t, "./...")
+}
+
+// Las plantillas usan APIs de goney que no existían en versiones anteriores;
+// la versión que se fija en go.mod debe ser la que las publica.
+func TestGoneyRequirePinsReleaseWithTemplateAPIs(t *testing.T) {
+	pinned := goneyModule + " v" + rootCmd.Version
+	if got := (projectData{}).GoneyRequire(); got != pinned {
+		t.Fatalf("project pins %q, want %q", got, pinned)
+	}
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	os.WriteFile("go.mod", []byte("module example.com/shop\n\ngo 1.23\n"), 0644)
+	useWriter(t, &fileWriter{out: io.Discard})
+	addGoneyRequire()
+	if gomod, _ := os.ReadFile("go.mod"); !strings.Contains(string(gomod), "require "+pinned+"\n") {
+		t.Fatalf("go.mod does not pin %q:\n%s", pinned, gomod)
+	}
+
+	changelog, err := os.ReadFile(filepath.Join(filepath.Dir(wd), "CHANGELOG.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, release, found := strings.Cut(string(changelog), "## ["+rootCmd.Version+"]")
+	if !found {
+		t.Fatalf("CHANGELOG.md has no entry for %s", rootCmd.Version)
+	}
+	release, _, _ = strings.Cut(release, "\n## [")
+	for _, api := range []string{"RegisterContextHandler", "transport.QueueHandle", "transport.NewValidationError", "transport.FieldViolation"} {
+		if !strings.Contains(release, api) {
+			t.Errorf("release %s does not include %s used by the templates", rootCmd.Version, api)
+		}
+	}
+}
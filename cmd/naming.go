@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// names reúne las variantes de un nombre de entrada ("order-items",
+// "OrderItem", "order_items") que usan los generadores. Los tipos y textos
+// van en singular y las tablas y rutas en plural; las tildes se quitan
+// porque las rutas de import de Go solo admiten ASCII.
+type names struct {
+	Words   []string
+	Package string // orderitems
+	Pascal  string // OrderItem, en singular
+	Camel   string // orderItem, en singular
+	Snake   string // order_items
+	Kebab   string // order-items
+	Dir     string // carpeta y archivos del módulo según naming.style
+	Table   string // order_items, en plural
+	Route   string // order-items, en plural
+	Human   string // order item, en singular
+}
+
+func newNames(input string) (names, error) {
+	words := splitName(input)
+	if len(words) == 0 {
+		return names{}, fmt.Errorf("nombre vacío")
+	}
+	for _, word := range words {
+		for _, r := range word {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return names{}, fmt.Errorf("nombre %q: carácter no válido %q", input, r)
+			}
+			if r > unicode.MaxASCII && transliterations[r] == 0 {
+				return names{}, fmt.Errorf("nombre %q: %q no es ASCII; las rutas de import de Go solo admiten ASCII", input, r)
+			}
+		}
+	}
+	if !unicode.IsLetter([]rune(words[0])[0]) {
+		return names{}, fmt.Errorf("nombre %q: debe empezar por una letra", input)
+	}
+
+	infl, err := newInflector(config.Naming.Language, config.Naming.Irregular, config.Naming.Uncountable)
+	if err != nil {
+		return names{}, err
+	}
+	// El inflector trabaja con las tildes, que cambian el plural en español
+	// (canción, canciones); se quitan después.
+	last := words[len(words)-1]
+	singular := asciiWords(append(words[:len(words)-1:len(words)-1], infl.Singular(last)))
+	plural := asciiWords(append(words[:len(words)-1:len(words)-1], infl.Plural(infl.Singular(last))))
+	words = asciiWords(words)
+
+	pkg := strings.Join(words, "")
+	if token.IsKeyword(pkg) {
+		return names{}, fmt.Errorf("nombre %q: %s es una palabra reservada de Go", input, pkg)
+	}
+
+	return names{
+		Words:   words,
+		Package: pkg,
+		Pascal:  pascalCase(singular),
+		Camel:   camelCase(singular),
+		Snake:   strings.Join(words, "_"),
+		Kebab:   strings.Join(words, "-"),
+		Table:   strings.Join(plural, "_"),
+		Route:   strings.Join(plural, "-"),
+		Human:   strings.Join(singular, " "),
+		Dir:     strings.Join(words, dirSeparator()),
+	}, nil
+}
+
+// transliterations quita tildes y diéresis de las letras latinas en
+// minúsculas; el resto de letras no ASCII se rechaza.
+var transliterations = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+func asciiWords(words []string) []string {
+	ascii := make([]string, len(words))
+	for i, word := range words {
+		ascii[i] = strings.Map(func(r rune) rune {
+			if plain, ok := transliterations[r]; ok {
+				return plain
+			}
+			return r
+		}, word)
+	}
+	return ascii
+}
+
+// dirSeparator une las palabras de carpetas y archivos según naming.style.
+func dirSeparator() string {
+	if config.Naming.Style == "snake" {
+		return "_"
+	}
+	return "-"
+}
+
+// splitName separa un nombre en palabras en minúsculas, aceptando
+// kebab-case, snake_case, puntos, espacios y camelCase ("UserService",
+// "order-items").
+func splitName(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '_' || r == '.' || unicode.IsSpace(r):
+			flush()
+		case unicode.IsUpper(r) && len(current) > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// initialisms se escriben en mayúsculas en los identificadores, como pide
+// el estilo de Go (UserID, APIKey).
+var initialisms = map[string]bool{
+	"api": true, "cpu": true, "css": true, "dns": true, "html": true, "http": true,
+	"https": true, "id": true, "ip": true, "json": true, "jwt": true, "sql": true,
+	"ssh": true, "tcp": true, "tls": true, "udp": true, "ui": true, "uri": true,
+	"url": true, "uuid": true, "xml": true,
+}
+
+func pascalCase(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		r := []rune(word)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+func camelCase(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return words[0] + pascalCase(words[1:])
+}
+
+// inflector pluraliza la última palabra de los nombres de tablas y rutas y
+// la pasa a singular en los de tipos.
+// Las entradas de naming en la configuración del proyecto se suman al
+// diccionario del idioma.
+type inflector struct {
+	Language    string
+	Irregular   map[string]string
+	Uncountable []string
+}
+
+var defaultInflections = map[string]inflector{
+	"en": {
+		Irregular: map[string]string{
+			"analysis": "analyses", "axis": "axes", "basis": "bases",
+			"child": "children", "crisis": "crises", "criterion": "criteria",
+			"diagnosis": "diagnoses", "foot": "feet", "goose": "geese",
+			"half": "halves", "knife": "knives", "leaf": "leaves", "life": "lives",
+			"man": "men", "mouse": "mice", "ox": "oxen", "person": "people",
+			"shelf": "shelves", "thief": "thieves", "tooth": "teeth",
+			"wife": "wives", "wolf": "wolves", "woman": "women",
+		},
+		Uncountable: []string{
+			"data", "equipment", "feedback", "fish", "information", "metadata",
+			"money", "news", "rice", "series", "sheep", "software", "species",
+		},
+	},
+	"es": {
+		Irregular: map[string]string{
+			"carácter": "caracteres", "examen": "exámenes", "joven": "jóvenes",
+			"mes": "meses", "orden": "órdenes", "país": "países", "régimen": "regímenes",
+		},
+		Uncountable: []string{
+			"análisis", "crisis", "lunes", "martes", "miércoles", "jueves",
+			"viernes", "tesis", "virus", "tórax",
+		},
+	},
+}
+
+func newInflector(language string, irregular map[string]string, uncountable []string) (inflector, error) {
+	base, ok := defaultInflections[language]
+	if !ok {
+		return inflector{}, fmt.Errorf("idioma no soportado %q (en, es)", language)
+	}
+	infl := inflector{
+		Language:    language,
+		Irregular:   map[string]string{},
+		Uncountable: append(append([]string{}, base.Uncountable...), uncountable...),
+	}
+	for singular, plural := range base.Irregular {
+		infl.Irregular[singular] = plural
+	}
+	for singular, plural := range irregular {
+		infl.Irregular[strings.ToLower(singular)] = strings.ToLower(plural)
+	}
+	return infl, nil
+}
+
+// Plural devuelve el plural de una palabra en minúsculas; si ya está en
+// plural la deja igual.
+func (in inflector) Plural(word string) string {
+	for _, w := range in.Uncountable {
+		if w == word {
+			return word
+		}
+	}
+	if plural, ok := in.Irregular[word]; ok {
+		return plural
+	}
+	for _, plural := range in.Irregular {
+		if plural == word {
+			return word
+		}
+	}
+	if in.Language == "es" {
+		return pluralES(word)
+	}
+	return pluralEN(word)
+}
+
+// Singular devuelve el singular de una palabra en minúsculas; si ya está en
+// singular la deja igual.
+func (in inflector) Singular(word string) string {
+	for _, w := range in.Uncountable {
+		if w == word {
+			return word
+		}
+	}
+	for singular, plural := range in.Irregular {
+		if plural == word {
+			return singular
+		}
+	}
+	if _, ok := in.Irregular[word]; ok {
+		return word
+	}
+	if in.Language == "es" {
+		return singularES(word)
+	}
+	return singularEN(word)
+}
+
+func pluralEN(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		return word + "es"
+	case strings.HasSuffix(word, "s"):
+		return word
+	case strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return strings.TrimSuffix(word, "y") + "ies"
+	}
+	return word + "s"
+}
+
+func singularEN(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "tuses"),
+		strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+var unaccented = map[rune]rune{'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u'}
+
+func pluralES(word string) string {
+	r := []rune(word)
+	last := r[len(r)-1]
+	switch {
+	case strings.ContainsRune("aeiouáéó", last):
+		return word + "s"
+	case strings.ContainsRune("íú", last):
+		return word + "es"
+	case last == 'z':
+		return string(r[:len(r)-1]) + "ces"
+	case last == 's' && (len(r) < 2 || unaccented[r[len(r)-2]] == 0):
+		// Sin tilde en la última sílaba ya es plural (users, productos).
+		return word
+	}
+	// Las agudas en -n o -s pierden la tilde: canción, canciones.
+	if len(r) > 1 && (last == 'n' || last == 's') {
+		if plain, ok := unaccented[r[len(r)-2]]; ok {
+			r[len(r)-2] = plain
+		}
+	}
+	return string(r) + "es"
+}
+
+func singularES(word string) string {
+	r := []rune(word)
+	if len(r) < 3 || r[len(r)-1] != 's' || unaccented[r[len(r)-2]] != 0 {
+		// Sin -s final, o aguda en -s (país, compás): ya es singular.
+		return word
+	}
+	stem := r[:len(r)-2]
+	switch {
+	case r[len(r)-2] != 'e':
+		return string(r[:len(r)-1])
+	case stem[len(stem)-1] == 'c':
+		return string(stem[:len(stem)-1]) + "z"
+	case strings.ContainsRune("dlnrjy", stem[len(stem)-1]) && !strings.HasSuffix(string(stem), "ll"):
+		// ciudades, papeles, canciones: el plural añadió -es.
+		return string(stem)
+	}
+	return string(r[:len(r)-1])
+}
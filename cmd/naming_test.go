@@ -0,0 +1,147 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewNames(t *testing.T) {
+	tests := []struct {
+		input string
+		want  names
+	}{
+		{"order-items", names{Package: "orderitems", Pascal: "OrderItem", Camel: "orderItem", Snake: "order_items", Kebab: "order-items", Table: "order_items", Route: "order-items"}},
+		{"OrderItem", names{Package: "orderitem", Pascal: "OrderItem", Camel: "orderItem", Snake: "order_item", Kebab: "order-item", Table: "order_items", Route: "order-items"}},
+		{"api_key", names{Package: "apikey", Pascal: "APIKey", Camel: "apiKey", Snake: "api_key", Kebab: "api-key", Table: "api_keys", Route: "api-keys"}},
+		{"category", names{Package: "category", Pascal: "Category", Camel: "category", Snake: "category", Kebab: "category", Table: "categories", Route: "categories"}},
+		{"person", names{Package: "person", Pascal: "Person", Camel: "person", Snake: "person", Kebab: "person", Table: "people", Route: "people"}},
+		{"address", names{Package: "address", Pascal: "Address", Camel: "address", Snake: "address", Kebab: "address", Table: "addresses", Route: "addresses"}},
+		{"categories", names{Package: "categories", Pascal: "Category", Camel: "category", Snake: "categories", Kebab: "categories", Table: "categories", Route: "categories"}},
+		{"café", names{Package: "cafe", Pascal: "Cafe", Camel: "cafe", Snake: "cafe", Kebab: "cafe", Table: "cafes", Route: "cafes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := newNames(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got.Words, got.Human, got.Dir = nil, "", ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNamesInvalid(t *testing.T) {
+	for _, input := range []string{"", "--", "2fa", "type", "users/admin", "user$", "заказ", "ordenß"} {
+		if _, err := newNames(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		language, word, want string
+	}{
+		{"en", "user", "users"},
+		{"en", "users", "users"},
+		{"en", "box", "boxes"},
+		{"en", "key", "keys"},
+		{"en", "status", "statuses"},
+		{"en", "child", "children"},
+		{"en", "children", "children"},
+		{"en", "data", "data"},
+		{"es", "usuario", "usuarios"},
+		{"es", "usuarios", "usuarios"},
+		{"es", "ciudad", "ciudades"},
+		{"es", "canción", "canciones"},
+		{"es", "inglés", "ingleses"},
+		{"es", "lápiz", "lápices"},
+		{"es", "rubí", "rubíes"},
+		{"es", "rey", "reyes"},
+		{"es", "mes", "meses"},
+		{"es", "crisis", "crisis"},
+	}
+	for _, tt := range tests {
+		infl, err := newInflector(tt.language, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := infl.Plural(tt.word); got != tt.want {
+			t.Errorf("%s: Plural(%q) = %q, want %q", tt.language, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := []struct {
+		language, word, want string
+	}{
+		{"en", "users", "user"},
+		{"en", "user", "user"},
+		{"en", "items", "item"},
+		{"en", "boxes", "box"},
+		{"en", "categories", "category"},
+		{"en", "statuses", "status"},
+		{"en", "status", "status"},
+		{"en", "addresses", "address"},
+		{"en", "people", "person"},
+		{"en", "data", "data"},
+		{"es", "usuarios", "usuario"},
+		{"es", "ciudades", "ciudad"},
+		{"es", "canciones", "cancion"},
+		{"es", "lápices", "lápiz"},
+		{"es", "clientes", "cliente"},
+		{"es", "calles", "calle"},
+		{"es", "órdenes", "orden"},
+		{"es", "país", "país"},
+		{"es", "mes", "mes"},
+		{"es", "crisis", "crisis"},
+	}
+	for _, tt := range tests {
+		infl, err := newInflector(tt.language, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := infl.Singular(tt.word); got != tt.want {
+			t.Errorf("%s: Singular(%q) = %q, want %q", tt.language, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestNamingConfig(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Naming = namingConfig{
+		Style:       "snake",
+		Language:    "es",
+		Irregular:   map[string]string{"pez": "peces"},
+		Uncountable: []string{"stock"},
+	}
+	useConfig(t, cfg)
+
+	for input, want := range map[string]string{"pedido": "pedidos", "pez": "peces", "stock": "stock", "item-pedido": "item_pedidos"} {
+		n, err := newNames(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Table != want {
+			t.Errorf("%s: table %q, want %q", input, n.Table, want)
+		}
+	}
+	if n, _ := newNames("OrderItem"); n.Dir != "order_item" {
+		t.Errorf("dir = %q, want order_item", n.Dir)
+	}
+	// Las tildes cuentan para el plural y luego se quitan.
+	n, err := newNames("canciones")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Package != "canciones" || n.Pascal != "Cancion" || n.Table != "canciones" || n.Human != "cancion" {
+		t.Errorf("unexpected names %+v", n)
+	}
+	if n, _ := newNames("canción"); n.Package != "cancion" || n.Table != "canciones" {
+		t.Errorf("unexpected names %+v", n)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	databases = []string{"postgres", "mysql", "sqlite", "none"}
+	presets   = []string{"minimal", "full"}
+)
+
+// projectOptions son las opciones de goney new. Deciden las dependencias de
+// go.mod, las secciones de config.go y .env, los servicios de
+// docker-compose.yml y si se genera el módulo de ejemplo.
+type projectOptions struct {
+	Database   string
+	Transports []string
+	Preset     string
+}
+
+func defaultProjectOptions() projectOptions {
+	return projectOptions{Database: "postgres", Transports: []string{"http"}, Preset: "minimal"}
+}
+
+func (o projectOptions) validate() error {
+	if !contains(databases, o.Database) {
+		return fmt.Errorf("base de datos %q no soportada (%s)", o.Database, strings.Join(databases, ", "))
+	}
+	if len(o.Transports) == 0 {
+		return fmt.Errorf("indica al menos un transporte (%s)", strings.Join(transports, ", "))
+	}
+	for _, t := range o.Transports {
+		if !contains(transports, t) {
+			return fmt.Errorf("transporte %q no soportado (%s)", t, strings.Join(transports, ", "))
+		}
+	}
+	if !contains(presets, o.Preset) {
+		return fmt.Errorf("preset %q no soportado (%s)", o.Preset, strings.Join(presets, ", "))
+	}
+	return nil
+}
+
+func (o projectOptions) HasTransport(name string) bool {
+	return contains(o.Transports, name)
+}
+
+// projectData es lo que reciben las plantillas project/*.
+type projectData struct {
+	projectOptions
+	ProjectName string
+	ModulePath  string
+}
+
+// Ports son los puertos que expone el contenedor de la aplicación.
+func (d projectData) Ports() []string {
+	var ports []string
+	for _, t := range [][2]string{{"http", "8080"}, {"grpc", "50051"}, {"tcp", "9000"}} {
+		if d.HasTransport(t[0]) {
+			ports = append(ports, t[1])
+		}
+	}
+	return ports
+}
+
+// GoneyRequire es la línea de go.mod del módulo de goney, que usa el
+// servidor TCP.
+func (d projectData) GoneyRequire() string {
+	return goneyModule + " v" + rootCmd.Version
+}
+
+// Services son los servicios de docker-compose.yml de los que depende la
+// aplicación.
+func (d projectData) Services() []string {
+	var services []string
+	if d.Database == "postgres" || d.Database == "mysql" {
+		services = append(services, d.Database)
+	}
+	if d.HasTransport("nats") {
+		services = append(services, "nats")
+	}
+	return services
+}
+
+// projectOptionsFromFlags lee --db, --transport y --preset. Los que no se
+// pasan se preguntan si la entrada es una terminal (salvo --yes) y si no
+// toman el valor por defecto.
+func projectOptionsFromFlags(cmd *cobra.Command) (projectOptions, error) {
+	opts := defaultProjectOptions()
+	yes, _ := cmd.Flags().GetBool("yes")
+	var p *prompter
+	if !yes && isTerminal(os.Stdin) {
+		p = newPrompter(os.Stdin, os.Stdout)
+	}
+
+	if cmd.Flags().Changed("db") {
+		opts.Database, _ = cmd.Flags().GetString("db")
+	} else if p != nil {
+		opts.Database = p.choose("Base de datos", databases, opts.Database)
+	}
+	if cmd.Flags().Changed("transport") {
+		opts.Transports, _ = cmd.Flags().GetStringSlice("transport")
+	} else if p != nil {
+		opts.Transports = p.chooseMany("Transportes", transports, opts.Transports)
+	}
+	if cmd.Flags().Changed("preset") {
+		opts.Preset, _ = cmd.Flags().GetString("preset")
+	} else if p != nil {
+		opts.Preset = p.choose("Preset (minimal: solo la estructura, full: carpetas comunes y módulo de ejemplo)", presets, opts.Preset)
+	}
+	return opts, opts.validate()
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompter hace las preguntas del asistente de goney new. Una respuesta vacía
+// acepta el valor por defecto y una no válida repite la pregunta.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *prompter) choose(question string, options []string, def string) string {
+	for {
+		answer, ok := p.ask(question, options, def)
+		if !ok || answer == "" {
+			return def
+		}
+		if choice, valid := pickOption(options, answer); valid {
+			return choice
+		}
+		fmt.Fprintf(p.out, "   Opción no válida: %s\n", answer)
+	}
+}
+
+// chooseMany acepta varias opciones separadas por comas o espacios.
+func (p *prompter) chooseMany(question string, options, def []string) []string {
+	for {
+		answer, ok := p.ask(question+" (separados por comas)", options, strings.Join(def, ","))
+		if !ok || answer == "" {
+			return def
+		}
+		var chosen []string
+		valid := true
+		for _, item := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			choice, ok := pickOption(options, item)
+			if !ok {
+				fmt.Fprintf(p.out, "   Opción no válida: %s\n", item)
+				valid = false
+				break
+			}
+			if !contains(chosen, choice) {
+				chosen = append(chosen, choice)
+			}
+		}
+		if valid && len(chosen) > 0 {
+			return chosen
+		}
+	}
+}
+
+// ask devuelve false cuando se acaba la entrada.
+func (p *prompter) ask(question string, options []string, def string) (string, bool) {
+	fmt.Fprintf(p.out, "? %s\n", question)
+	for i, option := range options {
+		fmt.Fprintf(p.out, "   %d) %s\n", i+1, option)
+	}
+	fmt.Fprintf(p.out, "  [%s]: ", def)
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(p.out)
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// pickOption acepta el nombre de la opción o su número.
+func pickOption(options []string, answer string) (string, bool) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1], true
+	}
+	if contains(options, answer) {
+		return answer, true
+	}
+	return "", false
+}
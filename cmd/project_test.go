@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrompter(t *testing.T) {
+	p := newPrompter(strings.NewReader("\n3\nfoo\nnats\ngrpc, 1 grpc\n"), io.Discard)
+	if got := p.choose("db", databases, "postgres"); got != "postgres" {
+		t.Errorf("empty answer = %q, want the default", got)
+	}
+	if got := p.choose("db", databases, "postgres"); got != "sqlite" {
+		t.Errorf("answer 3 = %q, want sqlite", got)
+	}
+	if got := p.choose("preset", transports, "http"); got != "nats" {
+		t.Errorf("after an invalid answer = %q, want nats", got)
+	}
+	if got := p.chooseMany("transport", transports, []string{"http"}); !reflect.DeepEqual(got, []string{"grpc", "http"}) {
+		t.Errorf("chooseMany = %v, want [grpc http]", got)
+	}
+	// Sin más entrada se usa el valor por defecto.
+	if got := p.choose("db", databases, "none"); got != "none" {
+		t.Errorf("at EOF = %q, want the default", got)
+	}
+}
+
+func TestProjectOptionsValidate(t *testing.T) {
+	for _, opts := range []projectOptions{
+		{Database: "oracle", Transports: []string{"http"}, Preset: "minimal"},
+		{Database: "none", Transports: nil, Preset: "minimal"},
+		{Database: "none", Transports: []string{"http", "mqtt"}, Preset: "minimal"},
+		{Database: "none", Transports: []string{"http"}, Preset: "huge"},
+	} {
+		if err := opts.validate(); err == nil {
+			t.Errorf("%+v: expected error", opts)
+		}
+	}
+	if err := defaultProjectOptions().validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateNewProject(t *testing.T) {
+	tests := []struct {
+		opts    projectOptions
+		require []string
+		omit    []string
+	}{
+		{
+			opts:    projectOptions{Database: "none", Transports: []string{"http"}, Preset: "minimal"},
+			require: []string{"gin-gonic"},
+			omit:    []string{"gorm", "grpc", "nats", "pkg/database/database.go", "src/modules/item", "postgres:", "Go-Ney/goney"},
+		},
+		{
+			opts:    projectOptions{Database: "postgres", Transports: []string{"http", "grpc", "nats", "tcp"}, Preset: "full"},
+			require: []string{"gorm.io/driver/postgres", "google.golang.org/grpc", "nats-io/nats.go", "pkg/database/database.go", "src/modules/item", "postgres:", "nats:"},
+		},
+		{
+			opts:    projectOptions{Database: "sqlite", Transports: []string{"tcp"}, Preset: "minimal"},
+			require: []string{"gorm.io/driver/sqlite", "DB_PATH", "TCP_PORT", "github.com/Go-Ney/goney v", "transport.NewTcpServer(cfg.Tcp.Port)", "module.Tcp.Start()"},
+			omit:    []string{"google.golang.org/grpc", "PORT=8080", "postgres:"},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.opts.Database + "-" + strings.Join(tt.opts.Transports, "-") + "-" + tt.opts.Preset
+		t.Run(name, func(t *testing.T) {
+			wd, _ := os.Getwd()
+			if err := os.Chdir(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(wd)
+			useWriter(t, &fileWriter{DryRun: true, out: io.Discard})
+			useConfig(t, defaultConfig())
+
+			// En dry-run el writer solo lista los archivos, así que se
+			// renderiza cada uno para comprobar el contenido.
+			var out bytes.Buffer
+			writer.out = &out
+			createNewProject("api", tt.opts)
+			if writer.Failed() {
+				t.Fatalf("createNewProject failed:\n%s", out.String())
+			}
+			if config.dir != "" {
+				t.Fatal("createNewProject left the global config pointing to the new project")
+			}
+
+			all := out.String()
+			data := projectData{projectOptions: tt.opts, ProjectName: "api", ModulePath: "api"}
+			for _, name := range templateNames() {
+				// application.go no depende de las opciones.
+				if !strings.HasPrefix(name, "project/") || name == "project/application.go" ||
+					(name == "project/database.go" && tt.opts.Database == "none") {
+					continue
+				}
+				content, err := executeTemplate(name, data)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if strings.HasSuffix(name, ".go") {
+					if _, err := parser.ParseFile(token.NewFileSet(), name, content, parser.AllErrors); err != nil {
+						t.Errorf("%s: %v\n%s", name, err, content)
+					}
+				}
+				all += string(content)
+			}
+			for _, s := range tt.require {
+				if !strings.Contains(all, s) {
+					t.Errorf("missing %q", s)
+				}
+			}
+			for _, s := range tt.omit {
+				if strings.Contains(all, s) {
+					t.Errorf("unexpected %q", s)
+				}
+			}
+			if _, err := os.Stat(filepath.Join("api", "goney.json")); !os.IsNotExist(err) {
+				t.Error("dry-run wrote goney.json")
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Las plantillas se incluyen en el binario. Un proyecto puede sustituir
+// cualquiera dejando su versión con el mismo nombre en la carpeta templates
+// de la configuración, .goney/templates por defecto (goney templates eject la
+// copia ahí).
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+const templateExt = ".tmpl"
+
+// loadTemplate devuelve el texto de la plantilla name ("crud/controller.go"),
+// dando prioridad a la del proyecto.
+func loadTemplate(name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(config.Templates, filepath.FromSlash(name)+templateExt))
+	if os.IsNotExist(err) {
+		content, err = embeddedTemplates.ReadFile(path.Join("templates", name+templateExt))
+		if err != nil {
+			return "", fmt.Errorf("plantilla %q no encontrada", name)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// templateNames lista las plantillas incluidas en el binario.
+func templateNames() []string {
+	var names []string
+	fs.WalkDir(embeddedTemplates, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, strings.TrimSuffix(strings.TrimPrefix(p, "templates/"), templateExt))
+		}
+		return err
+	})
+	return names
+}
+
+func renderString(text string, data interface{}) string {
+	var b strings.Builder
+	template.Must(template.New("path").Parse(text)).Execute(&b, data)
+	return b.String()
+}
+
+// renderTemplate ejecuta la plantilla name y escribe el resultado en path
+// con writer.
+func renderTemplate(path, name string, data interface{}) error {
+	content, err := executeTemplate(name, data)
+	if err != nil {
+		return writer.Fail(fmt.Errorf("%s: %w", path, err))
+	}
+	return writer.WriteFile(path, content)
+}
+
+func executeTemplate(name string, data interface{}) ([]byte, error) {
+	text, err := loadTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ejectTemplates copia a .goney/templates la plantilla indicada o todas las
+// de una carpeta ("crud").
+func ejectTemplates(name string) {
+	name = strings.Trim(strings.TrimSuffix(filepath.ToSlash(name), templateExt), "/")
+	var selected []string
+	for _, t := range templateNames() {
+		if t == name || strings.HasPrefix(t, name+"/") {
+			selected = append(selected, t)
+		}
+	}
+	if len(selected) == 0 {
+		failf("plantilla %q no encontrada (usa goney templates list)", name)
+		return
+	}
+
+	// Las ya personalizadas se respetan (salvo --force) y se sigue con el resto.
+	copied := 0
+	for _, t := range selected {
+		content, _ := embeddedTemplates.ReadFile(path.Join("templates", t+templateExt))
+		if writer.WriteFile(filepath.Join(config.Templates, filepath.FromSlash(t)+templateExt), content) == nil {
+			copied++
+		}
+	}
+	if copied > 0 {
+		fmt.Printf("✅ %d plantilla(s) copiadas a %s; los generadores usarán tu versión\n", copied, config.Templates)
+	}
+}
+
+func listTemplates() {
+	for _, t := range templateNames() {
+		marker := " "
+		if _, err := os.Stat(filepath.Join(config.Templates, filepath.FromSlash(t)+templateExt)); err == nil {
+			marker = "*"
+		}
+		fmt.Printf(" %s %s\n", marker, t)
+	}
+	fmt.Printf("\n* personalizada en %s\n", config.Templates)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestEmbeddedTemplatesParse(t *testing.T) {
+	names := templateNames()
+	if len(names) == 0 {
+		t.Fatal("no embedded templates")
+	}
+	for _, name := range names {
+		text, err := loadTemplate(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := template.New(name).Parse(text); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestTemplateOverride(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	useWriter(t, &fileWriter{out: io.Discard})
+
+	ejectTemplates("flat")
+	ejected, _ := filepath.Glob(filepath.Join(config.Templates, "flat", "*"+templateExt))
+	if len(ejected) != 5 {
+		t.Fatalf("ejected %v, want the 5 flat templates", ejected)
+	}
+
+	custom := "package {{.Package}}\n\n// Plantilla del equipo\ntype {{.ClassName}}Model struct{}\n"
+	os.WriteFile(filepath.Join(config.Templates, "flat", "model.go"+templateExt), []byte(custom), 0644)
+	generateModel("order-item")
+
+	got, err := os.ReadFile("src/modules/order-item/order-item.model.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "// Plantilla del equipo\ntype OrderItemModel struct{}") {
+		t.Fatalf("override not used:\n%s", got)
+	}
+
+	// Volver a expulsar no pisa la plantilla personalizada.
+	ejectTemplates("flat/model.go")
+	if !writer.Failed() {
+		t.Fatal("expected conflict when ejecting over a customized template")
+	}
+}
+
+func TestBrokenTemplateOverrideFailsDryRun(t *testing.T) {
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	useWriter(t, &fileWriter{DryRun: true, out: io.Discard})
+
+	os.MkdirAll(filepath.Join(config.Templates, "flat"), 0755)
+	broken := "package {{.Package}}\n\ntype {{.ClassName}}Model struct {\n"
+	os.WriteFile(filepath.Join(config.Templates, "flat", "model.go"+templateExt), []byte(broken), 0644)
+	generateModel("order-item")
+
+	if !writer.Failed() {
+		t.Fatal("expected --dry-run to fail with a template that renders invalid Go")
+	}
+	if _, err := os.Stat("src/modules/order-item/order-item.model.go"); !os.IsNotExist(err) {
+		t.Fatalf("dry-run wrote the model: %v", err)
+	}
+}
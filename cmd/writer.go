@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errFileExists = errors.New("el archivo ya existe con otro contenido (usa --force para sobrescribirlo)")
+
+// fileWriter es el único punto de escritura de los generadores: aplica gofmt
+// a los .go, no pisa archivos existentes sin --force (muestra el diff del
+// conflicto) y con --dry-run solo informa de lo que haría. Los errores quedan
+// registrados para que el comando termine con código distinto de cero.
+type fileWriter struct {
+	DryRun bool
+	Force  bool
+
+	out    io.Writer
+	failed bool
+}
+
+var writer = &fileWriter{out: os.Stdout}
+
+// WriteFile crea path o lo sobrescribe si su contenido coincide o se usó
+// --force.
+func (w *fileWriter) WriteFile(path string, content []byte) error {
+	return w.write(path, content, w.Force)
+}
+
+// UpdateFile modifica un archivo existente a propósito (app.module.go, .env)
+// sin pedir --force.
+func (w *fileWriter) UpdateFile(path string, content []byte) error {
+	return w.write(path, content, true)
+}
+
+func (w *fileWriter) write(path string, content []byte, overwrite bool) error {
+	var formatErr error
+	if strings.HasSuffix(path, ".go") {
+		if formatted, err := format.Source(content); err != nil {
+			formatErr = fmt.Errorf("%s: gofmt: %w", path, err)
+		} else {
+			content = formatted
+		}
+	}
+	// Un .go que no compila es un fallo aunque no se escriba (sin cambios o
+	// --dry-run).
+	formatFailure := func() error {
+		if formatErr != nil {
+			return w.Fail(formatErr)
+		}
+		return nil
+	}
+
+	action := "+"
+	current, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(current, content):
+		w.printf("   = %s (sin cambios)\n", path)
+		return formatFailure()
+	case err == nil && !overwrite:
+		w.printf("   ! %s\n", path)
+		w.out.Write(unifiedDiff(path, string(current), string(content)))
+		return w.Fail(fmt.Errorf("%s: %w", path, errFileExists))
+	case err == nil:
+		action = "~"
+	case !os.IsNotExist(err):
+		return w.Fail(err)
+	}
+
+	if w.DryRun {
+		w.printf("   %s %s (dry-run)\n", action, path)
+		return formatFailure()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return w.Fail(err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return w.Fail(err)
+	}
+	w.printf("   %s %s\n", action, path)
+	// Se escribe igualmente para poder revisar el código generado.
+	return formatFailure()
+}
+
+func (w *fileWriter) MkdirAll(path string) error {
+	if w.DryRun {
+		return nil
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return w.Fail(err)
+	}
+	return nil
+}
+
+func (w *fileWriter) RemoveAll(path string) error {
+	if w.DryRun {
+		w.printf("   - %s (dry-run)\n", path)
+		return nil
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return w.Fail(err)
+	}
+	w.printf("   - %s\n", path)
+	return nil
+}
+
+// Fail informa del error y marca la ejecución como fallida.
+func (w *fileWriter) Fail(err error) error {
+	w.failed = true
+	w.printf("❌ %v\n", err)
+	return err
+}
+
+func (w *fileWriter) Failed() bool {
+	return w.failed
+}
+
+func (w *fileWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.out, format, args...)
+}
+
+// failf es un atajo para los errores de los generadores que no vienen de
+// escribir un archivo.
+func failf(format string, args ...interface{}) {
+	writer.Fail(fmt.Errorf(format, args...))
+}
+
+// unifiedDiff devuelve un diff unificado, con 3 líneas de contexto, entre el
+// contenido actual del archivo y el que se generaría.
+func unifiedDiff(path, a, b string) []byte {
+	x, y := splitLines(a), splitLines(b)
+
+	// Tabla LCS; los archivos generados son pequeños.
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		kind byte
+		line string
+		i, j int
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			ops = append(ops, op{' ', x[i], i, j})
+			i, j = i+1, j+1
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', x[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{'+', y[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s (actual)\n+++ %s (generado)\n", path, path)
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+		// Agrupa los cambios separados por menos de 2*context líneas iguales.
+		from := max(start-context, 0)
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k
+			} else if k-end > 2*context {
+				break
+			}
+		}
+		to := min(end+context+1, len(ops))
+
+		var oldLines, newLines int
+		for _, o := range ops[from:to] {
+			if o.kind != '+' {
+				oldLines++
+			}
+			if o.kind != '-' {
+				newLines++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", ops[from].i+1, oldLines, ops[from].j+1, newLines)
+		for _, o := range ops[from:to] {
+			fmt.Fprintf(&buf, "%c%s\n", o.kind, o.line)
+		}
+		start = to
+	}
+	return buf.Bytes()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useWriter sustituye el writer global durante el test.
+func useWriter(t *testing.T, w *fileWriter) {
+	t.Helper()
+	prev := writer
+	writer = w
+	t.Cleanup(func() { writer = prev })
+}
+
+func TestFileWriter(t *testing.T) {
+	const src = "package x\nfunc  F() {}\n"
+	const formatted = "package x\n\nfunc F() {}\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		force    bool
+		dryRun   bool
+		want     string
+		wantErr  error
+		wantOut  string
+	}{
+		{name: "nuevo con gofmt", want: formatted, wantOut: "+ "},
+		{name: "sin cambios", existing: formatted, want: formatted, wantOut: "(sin cambios)"},
+		{name: "conflicto", existing: "package x\n\nfunc G() {}\n", want: "package x\n\nfunc G() {}\n", wantErr: errFileExists, wantOut: "-func G() {}\n+func F() {}"},
+		{name: "force", existing: "package x\n", force: true, want: formatted, wantOut: "~ "},
+		{name: "dry-run", dryRun: true, wantOut: "(dry-run)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sub", "x.go")
+			if tt.existing != "" {
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte(tt.existing), 0644)
+			}
+			var out bytes.Buffer
+			w := &fileWriter{DryRun: tt.dryRun, Force: tt.force, out: &out}
+
+			err := w.WriteFile(path, []byte(src))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if w.Failed() != (tt.wantErr != nil) {
+				t.Errorf("Failed() = %v", w.Failed())
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("output %q does not contain %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestFileWriterInvalidGo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.go")
+	w := &fileWriter{out: &bytes.Buffer{}}
+	if err := w.WriteFile(path, []byte("package x\nfunc {")); err == nil || !w.Failed() {
+		t.Fatalf("expected gofmt error, got %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("file should be written for inspection: %v", err)
+	}
+
+	// Sin cambios respecto al archivo ya escrito sigue siendo un fallo.
+	w = &fileWriter{out: &bytes.Buffer{}}
+	if err := w.WriteFile(path, []byte("package x\nfunc {")); err == nil || !w.Failed() {
+		t.Fatalf("expected gofmt error on unchanged file, got %v", err)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nx\n4\n5\n6\n7\n8\n9\n10\n11\ny\n"
+	want := `--- f (actual)
++++ f (generado)
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++x
+ 4
+ 5
+ 6
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++y
+`
+	if got := string(unifiedDiff("f", a, b)); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -1,22 +1,50 @@
 package core
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Go-Ney/goney/pkg/transport"
 	"github.com/gin-gonic/gin"
 )
 
 type Application struct {
 	engine *gin.Engine
 	config *Config
+	server *http.Server
+	// serverMu protege server, que Listen crea y Shutdown detiene desde
+	// otra goroutine.
+	serverMu sync.Mutex
+
+	grpcServer *transport.GrpcServer
+	indicators map[string]HealthIndicator
+	healthMu   sync.RWMutex
+	// healthTicker arranca una sola vez la reevaluación periódica de los
+	// indicadores, aunque se llame varias veces a UseGrpcServer.
+	healthTicker sync.Once
+	draining     atomic.Bool
+	stop         chan struct{}
 }
 
+// HealthIndicator devuelve un error si la dependencia no está disponible,
+// por ejemplo transport.NatsClient.HealthCheck.
+type HealthIndicator func() error
+
 type Config struct {
 	Port     string
 	Database DatabaseConfig
 	Grpc     GrpcConfig
 	Nats     NatsConfig
+
+	// HealthCheckInterval es cada cuánto se reevalúan los indicadores para
+	// publicar el estado en el servicio de salud gRPC (10s por defecto).
+	HealthCheckInterval time.Duration
 }
 
 type DatabaseConfig struct {
@@ -28,17 +56,127 @@ type DatabaseConfig struct {
 }
 
 type GrpcConfig struct {
-	Port string
+	Port    string
+	Address string
+
+	KeepaliveTime                time.Duration
+	KeepaliveTimeout             time.Duration
+	KeepaliveMinTime             time.Duration
+	KeepalivePermitWithoutStream bool
+	MaxConnectionIdle            time.Duration
+	MaxConnectionAge             time.Duration
+	MaxConnectionAgeGrace        time.Duration
+
+	MaxRecvMsgSize       int
+	MaxSendMsgSize       int
+	MaxConcurrentStreams uint32
+	ConnectionTimeout    time.Duration
+	Compression          string
+
+	// Clients declara los servicios gRPC externos por nombre.
+	Clients map[string]transport.GrpcClientConfig
+}
+
+// ServerOptions traduce la configuración a las opciones de transport.GrpcServer.
+func (c GrpcConfig) ServerOptions() transport.GrpcServerOptions {
+	options := transport.DefaultGrpcServerOptions()
+	if c.Port != "" {
+		options.Port = c.Port
+	}
+	options.Address = c.Address
+	options.KeepaliveTime = c.KeepaliveTime
+	options.KeepaliveTimeout = c.KeepaliveTimeout
+	options.KeepaliveMinTime = c.KeepaliveMinTime
+	options.KeepalivePermitWithoutStream = c.KeepalivePermitWithoutStream
+	options.MaxConnectionIdle = c.MaxConnectionIdle
+	options.MaxConnectionAge = c.MaxConnectionAge
+	options.MaxConnectionAgeGrace = c.MaxConnectionAgeGrace
+	options.MaxRecvMsgSize = c.MaxRecvMsgSize
+	options.MaxSendMsgSize = c.MaxSendMsgSize
+	options.MaxConcurrentStreams = c.MaxConcurrentStreams
+	options.ConnectionTimeout = c.ConnectionTimeout
+	options.Compression = c.Compression
+	return options
+}
+
+// ClientFactory devuelve una factoría con los clientes declarados en Clients.
+func (c GrpcConfig) ClientFactory() *transport.GrpcClientFactory {
+	factory := transport.NewGrpcClientFactory()
+	for name, client := range c.Clients {
+		factory.Register(name, client)
+	}
+	return factory
 }
 
 type NatsConfig struct {
-	URL string
+	URL             string
+	Servers         []string
+	Name            string
+	CredentialsFile string
+	NKeyFile        string
+	Token           string
+	User            string
+	Password        string
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSCAFile       string
+	ReconnectWait   time.Duration
+	MaxReconnects   int
+	PingInterval    time.Duration
+	DrainOnClose    bool
+	OldRequestStyle bool
+
+	// Buckets de JetStream para estado compartido (cache, throttle, config)
+	// y objetos grandes.
+	KeyValueBucket    string
+	KeyValueTTL       time.Duration
+	ObjectStoreBucket string
+}
+
+// ClientOptions traduce la configuración a las opciones de transport.NatsClient;
+// los valores vacíos conservan los valores por defecto.
+func (c NatsConfig) ClientOptions() transport.NatsOptions {
+	options := transport.DefaultNatsOptions()
+	if len(c.Servers) > 0 {
+		options.Servers = c.Servers
+	} else if c.URL != "" {
+		options.Servers = []string{c.URL}
+	}
+	options.Name = c.Name
+	options.CredentialsFile = c.CredentialsFile
+	options.NKeyFile = c.NKeyFile
+	options.Token = c.Token
+	options.User = c.User
+	options.Password = c.Password
+	options.TLSCertFile = c.TLSCertFile
+	options.TLSKeyFile = c.TLSKeyFile
+	options.TLSCAFile = c.TLSCAFile
+	options.PingInterval = c.PingInterval
+	options.DrainOnClose = c.DrainOnClose
+	options.OldRequestStyle = c.OldRequestStyle
+	if c.ReconnectWait > 0 {
+		options.ReconnectWait = c.ReconnectWait
+	}
+	if c.MaxReconnects != 0 {
+		options.MaxReconnects = c.MaxReconnects
+	}
+	return options
+}
+
+func (c NatsConfig) KeyValueConfig() transport.KeyValueConfig {
+	return transport.KeyValueConfig{Bucket: c.KeyValueBucket, TTL: c.KeyValueTTL}
+}
+
+func (c NatsConfig) ObjectStoreConfig() transport.ObjectStoreConfig {
+	return transport.ObjectStoreConfig{Bucket: c.ObjectStoreBucket}
 }
 
 func NewApplication(config *Config) *Application {
 	app := &Application{
-		engine: gin.Default(),
-		config: config,
+		engine:     gin.Default(),
+		config:     config,
+		indicators: make(map[string]HealthIndicator),
+		stop:       make(chan struct{}),
 	}
 
 	app.setupMiddleware()
@@ -76,15 +214,108 @@ func (a *Application) setupRoutes() {
 	api := a.engine.Group("/api/v1")
 
 	api.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
+		healthy, checks := a.CheckHealth()
+
+		status, code := "ok", http.StatusOK
+		if a.draining.Load() {
+			status, code = "draining", http.StatusServiceUnavailable
+		} else if !healthy {
+			status, code = "error", http.StatusServiceUnavailable
+		}
+
+		c.JSON(code, gin.H{
+			"status":  status,
 			"message": "Go-ney server is running",
 			"port":    a.config.Port,
 			"version": "1.0.1",
+			"checks":  checks,
 		})
 	})
 }
 
+// AddHealthIndicator agrega una dependencia al health check HTTP y al estado
+// global publicado por el servidor gRPC enlazado.
+func (a *Application) AddHealthIndicator(name string, indicator HealthIndicator) {
+	a.healthMu.Lock()
+	defer a.healthMu.Unlock()
+	a.indicators[name] = indicator
+}
+
+// CheckHealth evalúa los indicadores y sincroniza el resultado con el
+// servicio de salud gRPC, de modo que las sondas HTTP y gRPC coincidan: un
+// indicador caído marca como NOT_SERVING el estado global y el de cada
+// servicio.
+func (a *Application) CheckHealth() (bool, map[string]string) {
+	// Los indicadores se ejecutan sin el lock: pueden tardar o registrar
+	// otros indicadores.
+	a.healthMu.RLock()
+	indicators := make(map[string]HealthIndicator, len(a.indicators))
+	names := make([]string, 0, len(a.indicators))
+	for name, indicator := range a.indicators {
+		indicators[name] = indicator
+		names = append(names, name)
+	}
+	a.healthMu.RUnlock()
+	sort.Strings(names)
+
+	healthy := true
+	checks := make(map[string]string, len(names))
+	for _, name := range names {
+		if err := indicators[name](); err != nil {
+			healthy = false
+			checks[name] = err.Error()
+			continue
+		}
+		checks[name] = "ok"
+	}
+
+	if grpcServer := a.boundGrpcServer(); grpcServer != nil && !a.draining.Load() {
+		grpcServer.SetServing(healthy)
+	}
+	return healthy, checks
+}
+
+func (a *Application) boundGrpcServer() *transport.GrpcServer {
+	a.healthMu.RLock()
+	defer a.healthMu.RUnlock()
+	return a.grpcServer
+}
+
+// UseGrpcServer enlaza el servidor gRPC con el ciclo de vida de la
+// aplicación: su estado de salud sigue a los indicadores (también al
+// arrancar) y Shutdown lo drena. Una segunda llamada sustituye el servidor
+// enlazado.
+func (a *Application) UseGrpcServer(server *transport.GrpcServer) {
+	a.healthMu.Lock()
+	a.grpcServer = server
+	a.healthMu.Unlock()
+
+	server.SetHealthCheck(func() bool {
+		healthy, _ := a.CheckHealth()
+		return healthy
+	})
+	a.CheckHealth()
+
+	a.healthTicker.Do(func() {
+		interval := a.config.HealthCheckInterval
+		if interval <= 0 {
+			interval = 10 * time.Second
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-a.stop:
+					return
+				case <-ticker.C:
+					a.CheckHealth()
+				}
+			}
+		}()
+	})
+}
+
 func getWelcomeHTML() string {
 	return `<!DOCTYPE html>
 <html lang="es">
@@ -337,9 +568,71 @@ func getWelcomeHTML() string {
 </html>`
 }
 
+// MountGateway expone por HTTP/JSON los servicios del gateway en el router de
+// la aplicación; handlers permite aplicar los mismos guards que al resto de
+// rutas.
+func (a *Application) MountGateway(gateway *transport.Gateway, handlers ...gin.HandlerFunc) error {
+	return gateway.Mount(a.engine, handlers...)
+}
+
 func (a *Application) Listen(addr string) error {
 	fmt.Printf("🚀 Go-ney server starting on %s\n", addr)
-	return a.engine.Run(addr)
+	a.serverMu.Lock()
+	if a.draining.Load() {
+		a.serverMu.Unlock()
+		return nil
+	}
+	server := &http.Server{Addr: addr, Handler: a.engine}
+	a.server = server
+	a.serverMu.Unlock()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler expone el router para montarlo en otro servidor o en tests.
+func (a *Application) Handler() http.Handler {
+	return a.engine
+}
+
+// Shutdown marca la aplicación como drenando (los health checks HTTP y gRPC
+// pasan a fallar), espera a que terminen las peticiones en curso y detiene el
+// servidor gRPC enlazado.
+func (a *Application) Shutdown(ctx context.Context) error {
+	if !a.draining.CompareAndSwap(false, true) {
+		return nil
+	}
+	close(a.stop)
+
+	grpcServer := a.boundGrpcServer()
+	if grpcServer != nil {
+		grpcServer.Drain()
+	}
+
+	a.serverMu.Lock()
+	server := a.server
+	a.serverMu.Unlock()
+
+	var err error
+	if server != nil {
+		err = server.Shutdown(ctx)
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.Stop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.StopNow()
+		}
+	}
+	return err
 }
 
 func (a *Application) RegisterController(path string, controller interface{}) {
@@ -348,4 +641,4 @@ func (a *Application) RegisterController(path string, controller interface{}) {
 
 func (a *Application) Use(middleware gin.HandlerFunc) {
 	a.engine.Use(middleware)
-}
\ No newline at end of file
+}
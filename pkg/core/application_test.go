@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Go-Ney/goney/pkg/transport"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthIndicatorsDriveHTTPAndGrpcProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{Port: "8080", HealthCheckInterval: time.Hour})
+
+	grpcServer := transport.NewGrpcServerWithOptions(transport.DefaultGrpcServerOptions())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	app.UseGrpcServer(grpcServer)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	health := healthpb.NewHealthClient(conn)
+
+	probe := func() (int, healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		app.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		return recorder.Code, resp.Status
+	}
+
+	var dbErr error
+	app.AddHealthIndicator("database", func() error { return dbErr })
+
+	if code, status := probe(); code != http.StatusOK || status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected healthy probes, got %d/%v", code, status)
+	}
+
+	dbErr = errors.New("connection refused")
+	if code, status := probe(); code != http.StatusServiceUnavailable || status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected failing probes, got %d/%v", code, status)
+	}
+
+	dbErr = nil
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	app.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while draining, got %d", recorder.Code)
+	}
+}
+
+func TestGrpcServerStartsWithIndicatorStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{HealthCheckInterval: time.Hour})
+	app.AddHealthIndicator("database", func() error { return errors.New("connection refused") })
+
+	// El servidor se enlaza antes de arrancar, como en main.go: Serve no
+	// debe publicar SERVING sin consultar los indicadores.
+	grpcServer := transport.NewGrpcServerWithOptions(transport.DefaultGrpcServerOptions())
+	app.UseGrpcServer(grpcServer)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer app.Shutdown(context.Background())
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING, got %v", resp.Status)
+	}
+}
+
+// ordersService registra un servicio gRPC vacío para consultar su estado.
+type ordersService struct{}
+
+func (ordersService) RegisterWithServer(server *grpc.Server) {
+	server.RegisterService(&grpc.ServiceDesc{ServiceName: "test.Orders", HandlerType: (*interface{})(nil)}, struct{}{})
+}
+
+func TestHealthIndicatorsDriveServiceStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{HealthCheckInterval: time.Hour})
+
+	grpcServer := transport.NewGrpcServerWithOptions(transport.DefaultGrpcServerOptions())
+	grpcServer.RegisterService(ordersService{})
+	app.UseGrpcServer(grpcServer)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go grpcServer.Serve(lis)
+	defer app.Shutdown(context.Background())
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	health := healthpb.NewHealthClient(conn)
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{Service: "test.Orders"}, grpc.WaitForReady(true))
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		return resp.Status
+	}
+
+	if got := status(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %v", got)
+	}
+	app.AddHealthIndicator("database", func() error { return errors.New("connection refused") })
+	app.CheckHealth()
+	if got := status(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING, got %v", got)
+	}
+}
+
+func TestHealthIndicatorsRunWithoutLock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{})
+
+	// Un indicador que registra otro no debe bloquear el health check.
+	app.AddHealthIndicator("lazy", func() error {
+		app.AddHealthIndicator("cache", func() error { return nil })
+		return nil
+	})
+	done := make(chan struct{})
+	go func() {
+		app.CheckHealth()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("CheckHealth blocked while running indicators")
+	}
+}
+
+func TestUseGrpcServerTwiceStartsOneTicker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{HealthCheckInterval: time.Hour})
+	defer app.Shutdown(context.Background())
+
+	app.UseGrpcServer(transport.NewGrpcServerWithOptions(transport.DefaultGrpcServerOptions()))
+	goroutines := runtime.NumGoroutine()
+	app.UseGrpcServer(transport.NewGrpcServerWithOptions(transport.DefaultGrpcServerOptions()))
+	if got := runtime.NumGoroutine(); got > goroutines {
+		t.Fatalf("goroutines grew from %d to %d", goroutines, got)
+	}
+}
+
+func TestShutdownConcurrentWithUseGrpcServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{HealthCheckInterval: time.Hour})
+
+	done := make(chan struct{})
+	go func() {
+		app.UseGrpcServer(transport.NewGrpcServerWithOptions(transport.DefaultGrpcServerOptions()))
+		close(done)
+	}()
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	<-done
+}
+
+func TestShutdownConcurrentWithListen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	app := NewApplication(&Config{})
+
+	done := make(chan error, 1)
+	go func() { done <- app.Listen("127.0.0.1:0") }()
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Listen: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listen kept serving after Shutdown")
+	}
+}
@@ -1,8 +1,10 @@
 package guards
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,36 +13,76 @@ type Guard interface {
 	CanActivate(ctx *gin.Context) bool
 }
 
+// Errores de autenticación y autorización compartidos por los guards HTTP y
+// los interceptores de gRPC; el texto es el que se devuelve al cliente.
+var (
+	ErrMissingToken            = errors.New("Authorization header required")
+	ErrInvalidAuthFormat       = errors.New("Invalid authorization format")
+	ErrInvalidToken            = errors.New("Invalid token")
+	ErrRolesNotFound           = errors.New("User roles not found")
+	ErrInsufficientPermissions = errors.New("Insufficient permissions")
+)
+
+// TokenValidator valida el token y devuelve los roles del usuario.
+type TokenValidator func(token string) ([]string, error)
+
 type AuthGuard struct {
 	secretKey string
+	validator TokenValidator
 }
 
 func NewAuthGuard(secretKey string) *AuthGuard {
 	return &AuthGuard{secretKey: secretKey}
 }
 
+// NewAuthGuardWithValidator usa validator para verificar el token (por
+// ejemplo un JWT) y publica los roles que devuelve en "user_roles".
+func NewAuthGuardWithValidator(secretKey string, validator TokenValidator) *AuthGuard {
+	return &AuthGuard{secretKey: secretKey, validator: validator}
+}
+
+// HasValidator indica si el guard conoce los roles del usuario; sin
+// validador Authenticate nunca devuelve roles.
+func (g *AuthGuard) HasValidator() bool {
+	return g.validator != nil
+}
+
 func (g *AuthGuard) CanActivate(ctx *gin.Context) bool {
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+	roles, err := g.Authenticate(ctx.GetHeader("Authorization"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		ctx.Abort()
 		return false
 	}
 
+	if roles != nil {
+		ctx.Set("user_roles", roles)
+	}
+	return true
+}
+
+// Authenticate valida una cabecera "Bearer <token>" y devuelve los roles del
+// usuario, si el validador los conoce.
+func (g *AuthGuard) Authenticate(authHeader string) ([]string, error) {
+	if authHeader == "" {
+		return nil, ErrMissingToken
+	}
 	if !strings.HasPrefix(authHeader, "Bearer ") {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
-		ctx.Abort()
-		return false
+		return nil, ErrInvalidAuthFormat
 	}
 
 	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if g.validator != nil {
+		roles, err := g.validator(token)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		return roles, nil
+	}
 	if !g.validateToken(token) {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-		ctx.Abort()
-		return false
+		return nil, ErrInvalidToken
 	}
-
-	return true
+	return nil, nil
 }
 
 func (g *AuthGuard) validateToken(token string) bool {
@@ -58,7 +100,7 @@ func NewRoleGuard(roles ...string) *RoleGuard {
 func (g *RoleGuard) CanActivate(ctx *gin.Context) bool {
 	userRoles, exists := ctx.Get("user_roles")
 	if !exists {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "User roles not found"})
+		ctx.JSON(http.StatusForbidden, gin.H{"error": ErrRolesNotFound.Error()})
 		ctx.Abort()
 		return false
 	}
@@ -70,17 +112,25 @@ func (g *RoleGuard) CanActivate(ctx *gin.Context) bool {
 		return false
 	}
 
+	if err := g.Authorize(roles); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		ctx.Abort()
+		return false
+	}
+	return true
+}
+
+// Authorize comprueba que el usuario tenga al menos uno de los roles
+// requeridos.
+func (g *RoleGuard) Authorize(roles []string) error {
 	for _, requiredRole := range g.requiredRoles {
 		for _, userRole := range roles {
 			if userRole == requiredRole {
-				return true
+				return nil
 			}
 		}
 	}
-
-	ctx.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
-	ctx.Abort()
-	return false
+	return ErrInsufficientPermissions
 }
 
 func GuardMiddleware(guards ...Guard) gin.HandlerFunc {
@@ -94,27 +144,59 @@ func GuardMiddleware(guards ...Guard) gin.HandlerFunc {
 	}
 }
 
-type ThrottleGuard struct {
+// ThrottleStore cuenta las peticiones por cliente; la implementación en
+// memoria nunca reinicia el contador, transport.KVThrottleStore lo hace según
+// el TTL del bucket.
+type ThrottleStore interface {
+	Increment(key string) (int, error)
+}
+
+type MemoryThrottleStore struct {
+	mu       sync.Mutex
 	requests map[string]int
-	limit    int
+}
+
+func NewMemoryThrottleStore() *MemoryThrottleStore {
+	return &MemoryThrottleStore{requests: make(map[string]int)}
+}
+
+func (s *MemoryThrottleStore) Increment(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[key]++
+	return s.requests[key], nil
+}
+
+type ThrottleGuard struct {
+	store ThrottleStore
+	limit int
 }
 
 func NewThrottleGuard(limit int) *ThrottleGuard {
+	return NewThrottleGuardWithStore(limit, NewMemoryThrottleStore())
+}
+
+func NewThrottleGuardWithStore(limit int, store ThrottleStore) *ThrottleGuard {
 	return &ThrottleGuard{
-		requests: make(map[string]int),
-		limit:    limit,
+		store: store,
+		limit: limit,
 	}
 }
 
 func (g *ThrottleGuard) CanActivate(ctx *gin.Context) bool {
 	clientIP := ctx.ClientIP()
-	g.requests[clientIP]++
+	requests, err := g.store.Increment(clientIP)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Rate limit store unavailable"})
+		ctx.Abort()
+		return false
+	}
 
-	if g.requests[clientIP] > g.limit {
+	if requests > g.limit {
 		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
 		ctx.Abort()
 		return false
 	}
 
 	return true
-}
\ No newline at end of file
+}
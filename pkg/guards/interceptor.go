@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -126,31 +127,86 @@ func InterceptorMiddleware(interceptors ...Interceptor) gin.HandlerFunc {
 	}
 }
 
-type CacheInterceptor struct {
-	cache map[string]interface{}
+// CacheStore guarda las respuestas cacheadas; MemoryCacheStore es la
+// implementación por defecto y transport.KVCacheStore la comparte entre
+// instancias mediante NATS.
+type CacheStore interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+type MemoryCacheStore struct {
+	mu    sync.Mutex
+	cache map[string][]byte
 	ttl   map[string]time.Time
 }
 
-func NewCacheInterceptor() *CacheInterceptor {
-	return &CacheInterceptor{
-		cache: make(map[string]interface{}),
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		cache: make(map[string][]byte),
 		ttl:   make(map[string]time.Time),
 	}
 }
 
+func (s *MemoryCacheStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, exists := s.cache[key]
+	if !exists {
+		return nil, false, nil
+	}
+	if time.Now().After(s.ttl[key]) {
+		delete(s.cache, key)
+		delete(s.ttl, key)
+		return nil, false, nil
+	}
+	return value, true, nil
+}
+
+func (s *MemoryCacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache[key] = value
+	s.ttl[key] = time.Now().Add(ttl)
+	return nil
+}
+
+func (s *MemoryCacheStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cache, key)
+	delete(s.ttl, key)
+	return nil
+}
+
+type CacheInterceptor struct {
+	store CacheStore
+	ttl   time.Duration
+}
+
+func NewCacheInterceptor() *CacheInterceptor {
+	return NewCacheInterceptorWithStore(NewMemoryCacheStore(), 5*time.Minute)
+}
+
+func NewCacheInterceptorWithStore(store CacheStore, ttl time.Duration) *CacheInterceptor {
+	return &CacheInterceptor{store: store, ttl: ttl}
+}
+
 func (i *CacheInterceptor) Before(ctx *gin.Context) error {
 	if ctx.Request.Method == "GET" {
 		key := ctx.Request.URL.Path + "?" + ctx.Request.URL.RawQuery
 
-		if cachedResponse, exists := i.cache[key]; exists {
-			if time.Now().Before(i.ttl[key]) {
-				ctx.JSON(200, cachedResponse)
-				ctx.Abort()
-				return nil
-			} else {
-				delete(i.cache, key)
-				delete(i.ttl, key)
-			}
+		cachedResponse, exists, err := i.store.Get(key)
+		if err != nil {
+			return err
+		}
+		if exists {
+			ctx.Data(200, "application/json; charset=utf-8", cachedResponse)
+			ctx.Abort()
 		}
 	}
 	return nil
@@ -159,8 +215,11 @@ func (i *CacheInterceptor) Before(ctx *gin.Context) error {
 func (i *CacheInterceptor) After(ctx *gin.Context, response interface{}) error {
 	if ctx.Request.Method == "GET" && ctx.Writer.Status() == 200 {
 		key := ctx.Request.URL.Path + "?" + ctx.Request.URL.RawQuery
-		i.cache[key] = response
-		i.ttl[key] = time.Now().Add(5 * time.Minute)
+		data, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		return i.store.Set(key, data, i.ttl)
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,70 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ContextHandler es la variante de TcpHandler/NatsHandler que recibe el
+// contexto de la petición. El contexto se cancela cuando se cierra la
+// conexión, se cancela la suscripción o se detiene el servidor.
+type ContextHandler func(ctx context.Context, data []byte) ([]byte, error)
+
+type Metadata struct {
+	Transport  string
+	Operation  string
+	RemoteAddr net.Addr
+	LocalAddr  net.Addr
+	TLS        *tls.ConnectionState
+	Subject    string
+	Reply      string
+	Header     nats.Header
+}
+
+type contextKey int
+
+const (
+	metadataKey contextKey = iota
+	identityKey
+	requestIDKey
+)
+
+func ContextWithMetadata(ctx context.Context, md *Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey, md)
+}
+
+func MetadataFromContext(ctx context.Context) (*Metadata, bool) {
+	md, ok := ctx.Value(metadataKey).(*Metadata)
+	return md, ok
+}
+
+// ContextWithIdentity guarda la identidad autenticada (usuario, claims, etc.)
+// para que los handlers la lean con IdentityFromContext.
+func ContextWithIdentity(ctx context.Context, identity interface{}) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+func IdentityFromContext(ctx context.Context) (interface{}, bool) {
+	identity := ctx.Value(identityKey)
+	return identity, identity != nil
+}
+
+// ContextWithRequestID fija el identificador de petición que los clientes
+// gRPC propagan a los servicios que llaman.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok && requestID != ""
+}
+
+func adaptHandler(handler func([]byte) ([]byte, error)) ContextHandler {
+	return func(ctx context.Context, data []byte) ([]byte, error) {
+		return handler(data)
+	}
+}
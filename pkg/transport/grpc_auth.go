@@ -0,0 +1,159 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Go-Ney/goney/pkg/guards"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GrpcAuthPolicy define el acceso a un método: público, o autenticado y con
+// al menos uno de Roles si se indican.
+type GrpcAuthPolicy struct {
+	Public bool     `json:"public,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+type GrpcAuthConfig struct {
+	// Guard es obligatorio; las políticas con Roles requieren que se cree
+	// con guards.NewAuthGuardWithValidator, porque sin validador no hay
+	// roles que comprobar.
+	Guard *guards.AuthGuard `json:"-"`
+	// Default se aplica a los métodos que no aparecen en Methods.
+	Default GrpcAuthPolicy `json:"default"`
+	// Methods admite el método completo ("/users.Users/Delete") o todo un
+	// servicio ("/users.Users/*").
+	Methods map[string]GrpcAuthPolicy `json:"methods,omitempty"`
+}
+
+// GrpcIdentity es la identidad que dejan los interceptores de auth en el
+// contexto (IdentityFromContext).
+type GrpcIdentity struct {
+	Token string
+	Roles []string
+}
+
+func (c GrpcAuthConfig) policy(fullMethod string) GrpcAuthPolicy {
+	if policy, ok := c.Methods[fullMethod]; ok {
+		return policy
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+		if policy, ok := c.Methods[fullMethod[:i]+"/*"]; ok {
+			return policy
+		}
+	}
+	// Salvo que se configure, las sondas de salud no envían credenciales.
+	if strings.HasPrefix(fullMethod, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return GrpcAuthPolicy{Public: true}
+	}
+	return c.Default
+}
+
+// validate comprueba la configuración al crear los interceptores, en vez de
+// denegar todas las llamadas en tiempo de ejecución.
+func (c GrpcAuthConfig) validate() error {
+	if c.Guard == nil {
+		return errors.New("grpc auth: guard required")
+	}
+	if c.Guard.HasValidator() {
+		return nil
+	}
+	if len(c.Default.Roles) > 0 {
+		return errors.New("grpc auth: default policy requires roles but the guard has no validator")
+	}
+	for method, policy := range c.Methods {
+		if len(policy.Roles) > 0 {
+			return fmt.Errorf("grpc auth: %s requires roles but the guard has no validator", method)
+		}
+	}
+	return nil
+}
+
+// authorize valida la cabecera authorization de la metadata con la misma
+// lógica que guards.AuthGuard y guards.RoleGuard.
+func (c GrpcAuthConfig) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	policy := c.policy(fullMethod)
+	if policy.Public {
+		return ctx, nil
+	}
+
+	header := incomingValue(ctx, AuthorizationMetadataKey)
+	roles, err := c.Guard.Authenticate(header)
+	if err != nil {
+		return nil, GrpcStatus(fmt.Errorf("%w: %v", ErrUnauthorized, err)).Err()
+	}
+
+	if len(policy.Roles) > 0 {
+		if roles == nil {
+			return nil, GrpcStatus(fmt.Errorf("%w: %v", ErrForbidden, guards.ErrRolesNotFound)).Err()
+		}
+		if err := guards.NewRoleGuard(policy.Roles...).Authorize(roles); err != nil {
+			return nil, GrpcStatus(fmt.Errorf("%w: %v", ErrForbidden, err)).Err()
+		}
+	}
+
+	token := strings.TrimPrefix(header, "Bearer ")
+	return ContextWithIdentity(ctx, &GrpcIdentity{Token: token, Roles: roles}), nil
+}
+
+func AuthUnaryServerInterceptor(config GrpcAuthConfig) (grpc.UnaryServerInterceptor, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := config.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}, nil
+}
+
+func AuthStreamServerInterceptor(config GrpcAuthConfig) (grpc.StreamServerInterceptor, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := config.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ctx})
+	}, nil
+}
+
+// GrpcAuthMiddleware aplica la política como middleware de transporte, para
+// instalarla en un GrpcServer ya creado con Use.
+func GrpcAuthMiddleware(config GrpcAuthConfig) (Middleware, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			var operation string
+			if md, ok := MetadataFromContext(ctx); ok {
+				operation = md.Operation
+			}
+			ctx, err := config.authorize(ctx, operation)
+			if err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}, nil
+}
+
+// UseAuth protege todas las llamadas unarias y de streaming del servidor;
+// debe llamarse antes de Start.
+func (s *GrpcServer) UseAuth(config GrpcAuthConfig) error {
+	middleware, err := GrpcAuthMiddleware(config)
+	if err != nil {
+		return err
+	}
+	s.Use(middleware)
+	return nil
+}
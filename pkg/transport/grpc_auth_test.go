@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Go-Ney/goney/pkg/guards"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcAuthPolicies(t *testing.T) {
+	guard := guards.NewAuthGuardWithValidator("secret", func(token string) ([]string, error) {
+		switch token {
+		case "admin-token":
+			return []string{"admin"}, nil
+		case "user-token":
+			return []string{"user"}, nil
+		}
+		return nil, errors.New("unknown token")
+	})
+
+	var identity *GrpcIdentity
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	err := server.UseAuth(GrpcAuthConfig{
+		Guard: guard,
+		Methods: map[string]GrpcAuthPolicy{
+			"/grpc.health.v1.Health/Check": {Roles: []string{"admin"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("UseAuth: %v", err)
+	}
+	server.Use(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if value, ok := IdentityFromContext(ctx); ok {
+				identity = value.(*GrpcIdentity)
+			}
+			return next(ctx, request)
+		}
+	})
+	client := healthpb.NewHealthClient(runGrpcServer(t, server))
+
+	call := func(token string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, "Bearer "+token)
+		}
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	if err := call(""); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without token, got %v", err)
+	}
+	if err := call("forged"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for invalid token, got %v", err)
+	}
+	if err := call("user-token"); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for missing role, got %v", err)
+	}
+	if err := call("admin-token"); err != nil {
+		t.Fatalf("expected admin to pass, got %v", err)
+	}
+	if identity == nil || identity.Token != "admin-token" || identity.Roles[0] != "admin" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+}
+
+func TestGrpcAuthPolicyLookup(t *testing.T) {
+	config := GrpcAuthConfig{
+		Default: GrpcAuthPolicy{Roles: []string{"user"}},
+		Methods: map[string]GrpcAuthPolicy{
+			"/users.Users/*":     {Public: true},
+			"/users.Users/Purge": {Roles: []string{"admin"}},
+		},
+	}
+
+	if !config.policy("/users.Users/List").Public {
+		t.Fatal("expected service wildcard to apply")
+	}
+	if got := config.policy("/users.Users/Purge").Roles; len(got) != 1 || got[0] != "admin" {
+		t.Fatalf("expected exact method policy, got %v", got)
+	}
+	if got := config.policy("/orders.Orders/Get").Roles; len(got) != 1 || got[0] != "user" {
+		t.Fatalf("expected default policy, got %v", got)
+	}
+	if !config.policy("/grpc.health.v1.Health/Check").Public {
+		t.Fatal("expected health checks to be public by default")
+	}
+}
+
+func TestGrpcAuthConfigValidation(t *testing.T) {
+	if _, err := GrpcAuthMiddleware(GrpcAuthConfig{}); err == nil {
+		t.Fatal("expected error without guard")
+	}
+	if _, err := AuthUnaryServerInterceptor(GrpcAuthConfig{}); err == nil {
+		t.Fatal("expected error without guard")
+	}
+
+	// Sin validador el guard no conoce roles: una política con roles
+	// denegaría siempre.
+	guard := guards.NewAuthGuard("secret")
+	roles := GrpcAuthConfig{Guard: guard, Methods: map[string]GrpcAuthPolicy{"/users.Users/*": {Roles: []string{"admin"}}}}
+	if _, err := AuthStreamServerInterceptor(roles); err == nil {
+		t.Fatal("expected error for a role policy without validator")
+	}
+	if _, err := GrpcAuthMiddleware(GrpcAuthConfig{Guard: guard, Default: GrpcAuthPolicy{Roles: []string{"admin"}}}); err == nil {
+		t.Fatal("expected error for a default role policy without validator")
+	}
+	if _, err := GrpcAuthMiddleware(GrpcAuthConfig{Guard: guard}); err != nil {
+		t.Fatalf("authentication only should not need a validator: %v", err)
+	}
+}
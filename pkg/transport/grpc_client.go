@@ -0,0 +1,366 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+)
+
+// Cabeceras (metadata) que propagan los interceptores de cliente.
+const (
+	RequestIDMetadataKey     = "x-request-id"
+	AuthorizationMetadataKey = "authorization"
+)
+
+var ErrGrpcClientNotFound = errors.New("grpc client not registered")
+
+type GrpcRetryPolicy struct {
+	MaxAttempts       int           `json:"max_attempts"`
+	InitialBackoff    time.Duration `json:"initial_backoff"`
+	MaxBackoff        time.Duration `json:"max_backoff"`
+	BackoffMultiplier float64       `json:"backoff_multiplier"`
+	// RetryableCodes por defecto: UNAVAILABLE.
+	RetryableCodes []codes.Code `json:"retryable_codes,omitempty"`
+}
+
+type GrpcClientConfig struct {
+	// Target acepta cualquier URI de resolver de gRPC, por ejemplo
+	// "dns:///orders:50051". Si se indican Addresses se ignora y se reparte
+	// entre esas direcciones estáticas.
+	Target    string   `json:"target,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+
+	// LoadBalancing es "round_robin" o "pick_first" (por defecto).
+	LoadBalancing string `json:"load_balancing,omitempty"`
+
+	// Sin TLS ni ficheros de certificados la conexión va en claro.
+	TLS           *tls.Config `json:"-"`
+	TLSCAFile     string      `json:"tls_ca_file,omitempty"`
+	TLSCertFile   string      `json:"tls_cert_file,omitempty"`
+	TLSKeyFile    string      `json:"tls_key_file,omitempty"`
+	TLSServerName string      `json:"tls_server_name,omitempty"`
+
+	// DialTimeout bloquea la creación de la conexión hasta que esté lista;
+	// con 0 se conecta en segundo plano.
+	DialTimeout time.Duration `json:"dial_timeout,omitempty"`
+	// Timeout es el deadline por llamada que aplica gRPC si el contexto no
+	// trae uno.
+	Timeout time.Duration    `json:"timeout,omitempty"`
+	Retry   *GrpcRetryPolicy `json:"retry,omitempty"`
+
+	// ForwardAuth reenvía la cabecera authorization de la petición entrante.
+	ForwardAuth bool        `json:"forward_auth,omitempty"`
+	Logger      *log.Logger `json:"-"`
+
+	UnaryInterceptors  []grpc.UnaryClientInterceptor  `json:"-"`
+	StreamInterceptors []grpc.StreamClientInterceptor `json:"-"`
+	DialOptions        []grpc.DialOption              `json:"-"`
+}
+
+// DialGrpc crea una conexión con la configuración indicada. Siempre propaga
+// el request ID, generándolo si la petición no trae uno.
+func DialGrpc(config GrpcClientConfig) (*grpc.ClientConn, error) {
+	creds, err := config.credentials()
+	if err != nil {
+		return nil, err
+	}
+	serviceConfig, err := config.serviceConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	unary := []grpc.UnaryClientInterceptor{RequestIDClientInterceptor()}
+	stream := []grpc.StreamClientInterceptor{RequestIDStreamClientInterceptor()}
+	if config.ForwardAuth {
+		unary = append(unary, AuthForwardingClientInterceptor())
+		stream = append(stream, AuthForwardingStreamClientInterceptor())
+	}
+	if config.Logger != nil {
+		unary = append(unary, LoggingClientInterceptor(config.Logger))
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithChainUnaryInterceptor(append(unary, config.UnaryInterceptors...)...),
+		grpc.WithChainStreamInterceptor(append(stream, config.StreamInterceptors...)...),
+	}
+
+	target := config.Target
+	if len(config.Addresses) > 0 {
+		r := manual.NewBuilderWithScheme("goney" + newRequestID()[:8])
+		addresses := make([]resolver.Address, len(config.Addresses))
+		for i, addr := range config.Addresses {
+			addresses[i] = resolver.Address{Addr: addr}
+		}
+		r.InitialState(resolver.State{Addresses: addresses})
+		opts = append(opts, grpc.WithResolvers(r))
+		target = r.Scheme() + ":///static"
+	}
+	if target == "" {
+		return nil, errors.New("grpc client: target or addresses required")
+	}
+	opts = append(opts, config.DialOptions...)
+
+	if config.DialTimeout <= 0 {
+		return grpc.Dial(target, opts...)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout)
+	defer cancel()
+	return grpc.DialContext(ctx, target, append(opts, grpc.WithBlock())...)
+}
+
+func (c GrpcClientConfig) credentials() (credentials.TransportCredentials, error) {
+	if c.TLS == nil && c.TLSCAFile == "" && c.TLSCertFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLS != nil {
+		config = c.TLS.Clone()
+	}
+	if c.TLSServerName != "" {
+		config.ServerName = c.TLSServerName
+	}
+	if c.TLSCAFile != "" {
+		pem, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("grpc client: no certificates found in %s", c.TLSCAFile)
+		}
+		config.RootCAs = pool
+	}
+	if c.TLSCertFile != "" && c.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// serviceConfig genera el service config de gRPC con la política de balanceo,
+// el timeout y los reintentos de todos los métodos.
+func (c GrpcClientConfig) serviceConfig() (string, error) {
+	methodConfig := map[string]interface{}{
+		"name": []map[string]string{{}},
+	}
+	if c.Timeout > 0 {
+		methodConfig["timeout"] = durationJSON(c.Timeout)
+	}
+	if c.Retry != nil {
+		methodConfig["retryPolicy"] = c.Retry.serviceConfig()
+	}
+
+	balancer := c.LoadBalancing
+	if balancer == "" {
+		balancer = "pick_first"
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"loadBalancingConfig": []map[string]interface{}{{balancer: map[string]interface{}{}}},
+		"methodConfig":        []interface{}{methodConfig},
+	})
+	return string(data), err
+}
+
+func (p GrpcRetryPolicy) serviceConfig() map[string]interface{} {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 2 {
+		maxAttempts = 2
+	}
+	initialBackoff := p.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = 100 * time.Millisecond
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Second
+	}
+	multiplier := p.BackoffMultiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	retryable := p.RetryableCodes
+	if len(retryable) == 0 {
+		retryable = []codes.Code{codes.Unavailable}
+	}
+
+	return map[string]interface{}{
+		"maxAttempts":          maxAttempts,
+		"initialBackoff":       durationJSON(initialBackoff),
+		"maxBackoff":           durationJSON(maxBackoff),
+		"backoffMultiplier":    multiplier,
+		"retryableStatusCodes": retryable,
+	}
+}
+
+func durationJSON(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// GrpcClientFactory mantiene una conexión por destino configurado, creada la
+// primera vez que se pide.
+type GrpcClientFactory struct {
+	mu      sync.Mutex
+	configs map[string]GrpcClientConfig
+	conns   map[string]*grpc.ClientConn
+}
+
+func NewGrpcClientFactory() *GrpcClientFactory {
+	return &GrpcClientFactory{
+		configs: make(map[string]GrpcClientConfig),
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+func (f *GrpcClientFactory) Register(name string, config GrpcClientConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configs[name] = config
+}
+
+func (f *GrpcClientFactory) Conn(name string) (*grpc.ClientConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if conn, ok := f.conns[name]; ok {
+		return conn, nil
+	}
+	config, ok := f.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGrpcClientNotFound, name)
+	}
+	conn, err := DialGrpc(config)
+	if err != nil {
+		return nil, err
+	}
+	f.conns[name] = conn
+	return conn, nil
+}
+
+func (f *GrpcClientFactory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for name, conn := range f.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(f.conns, name)
+	}
+	return errors.Join(errs...)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func incomingValue(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDMetadataKey)) > 0 {
+		return ctx
+	}
+	requestID, ok := RequestIDFromContext(ctx)
+	if !ok {
+		requestID = incomingValue(ctx, RequestIDMetadataKey)
+	}
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDMetadataKey, requestID)
+}
+
+func withForwardedAuth(ctx context.Context) context.Context {
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(AuthorizationMetadataKey)) > 0 {
+		return ctx
+	}
+	if token := incomingValue(ctx, AuthorizationMetadataKey); token != "" {
+		return metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, token)
+	}
+	return ctx
+}
+
+// RequestIDClientInterceptor propaga el request ID del contexto
+// (ContextWithRequestID o la metadata entrante) o genera uno nuevo.
+func RequestIDClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func RequestIDStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+// AuthForwardingClientInterceptor reenvía el token de la llamada entrante,
+// para servicios que llaman a otros en nombre del usuario.
+func AuthForwardingClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withForwardedAuth(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+func AuthForwardingStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withForwardedAuth(ctx), desc, cc, method, opts...)
+	}
+}
+
+// TokenClientInterceptor agrega "Bearer <token>" con el token que devuelve
+// source, por ejemplo para credenciales de servicio.
+func TokenClientInterceptor(source func(ctx context.Context) (string, error)) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		token, err := source(ctx)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, "Bearer "+token)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func LoggingClientInterceptor(logger *log.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logger.Printf("grpc client %s %s (%s)", method, status.Code(err), time.Since(start))
+		return err
+	}
+}
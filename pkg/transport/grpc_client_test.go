@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func startGrpcServer(t *testing.T, mws ...Middleware) string {
+	t.Helper()
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	server.Use(mws...)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go server.Serve(lis)
+	t.Cleanup(server.StopNow)
+	return lis.Addr().String()
+}
+
+func TestGrpcClientRoundRobinAndRetry(t *testing.T) {
+	var hits [2]atomic.Int32
+	var failures atomic.Int32
+	counter := func(i int) Middleware {
+		return func(next Endpoint) Endpoint {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				hits[i].Add(1)
+				// La primera llamada falla para forzar un reintento.
+				if failures.CompareAndSwap(0, 1) {
+					return nil, status.Error(codes.Unavailable, "warming up")
+				}
+				return next(ctx, request)
+			}
+		}
+	}
+
+	factory := NewGrpcClientFactory()
+	defer factory.Close()
+	factory.Register("health", GrpcClientConfig{
+		Addresses:     []string{startGrpcServer(t, counter(0)), startGrpcServer(t, counter(1))},
+		LoadBalancing: "round_robin",
+		DialTimeout:   2 * time.Second,
+		Timeout:       2 * time.Second,
+		Retry:         &GrpcRetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond},
+	})
+
+	conn, err := factory.Conn("health")
+	if err != nil {
+		t.Fatalf("Conn: %v", err)
+	}
+	client := healthpb.NewHealthClient(conn)
+	for i := 0; i < 10; i++ {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatalf("Check %d: %v", i, err)
+		}
+	}
+
+	if hits[0].Load() == 0 || hits[1].Load() == 0 {
+		t.Fatalf("expected calls on both backends, got %d/%d", hits[0].Load(), hits[1].Load())
+	}
+	if total := hits[0].Load() + hits[1].Load(); total != 11 {
+		t.Fatalf("expected 11 attempts (10 calls + 1 retry), got %d", total)
+	}
+
+	if _, err := factory.Conn("missing"); err == nil {
+		t.Fatal("expected error for unregistered client")
+	}
+}
+
+func TestGrpcClientPropagatesRequestIDAndAuth(t *testing.T) {
+	var mu sync.Mutex
+	var received metadata.MD
+	capture := func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			mu.Lock()
+			received, _ = metadata.FromIncomingContext(ctx)
+			mu.Unlock()
+			return next(ctx, request)
+		}
+	}
+
+	conn, err := DialGrpc(GrpcClientConfig{Target: startGrpcServer(t, capture), ForwardAuth: true})
+	if err != nil {
+		t.Fatalf("DialGrpc: %v", err)
+	}
+	defer conn.Close()
+
+	// Simula el contexto de una petición entrante en un servicio intermedio.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		RequestIDMetadataKey, "req-123",
+		AuthorizationMetadataKey, "Bearer user-token",
+	))
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := received.Get(RequestIDMetadataKey); len(got) != 1 || got[0] != "req-123" {
+		t.Fatalf("unexpected request id: %v", got)
+	}
+	if got := received.Get(AuthorizationMetadataKey); len(got) != 1 || got[0] != "Bearer user-token" {
+		t.Fatalf("unexpected authorization: %v", got)
+	}
+}
@@ -0,0 +1,175 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Errores del framework; los servicios los envuelven con
+// fmt.Errorf("user %s: %w", id, transport.ErrNotFound) y el transporte los
+// traduce al código adecuado.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrTimeout      = errors.New("timeout")
+)
+
+// ErrorDomain es el dominio informado en errdetails.ErrorInfo.
+const ErrorDomain = "goney"
+
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// ValidationError lista los campos inválidos; errors.Is(err, ErrValidation)
+// devuelve true.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func NewValidationError(violations ...FieldViolation) *ValidationError {
+	return &ValidationError{Violations: violations}
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		fields[i] = fmt.Sprintf("%s: %s", violation.Field, violation.Description)
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(fields, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// GrpcStatus traduce un error a un status de gRPC. Los errores que ya son
+// status se respetan y los desconocidos quedan como codes.Unknown.
+func GrpcStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	code, reason := grpcCode(err)
+	message := err.Error()
+	if errors.Is(err, ErrHandlerPanic) {
+		message = ErrHandlerPanic.Error()
+	}
+	var remoteErr *RemoteError
+	if errors.As(err, &remoteErr) {
+		message = remoteErr.Message
+	}
+
+	st := status.New(code, message)
+	if reason == "" {
+		return st
+	}
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain}}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range validationErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Description,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+func grpcCode(err error) (codes.Code, string) {
+	var remoteErr *RemoteError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return codes.NotFound, "NOT_FOUND"
+	case errors.Is(err, ErrValidation):
+		return codes.InvalidArgument, "VALIDATION_FAILED"
+	case errors.Is(err, ErrUnauthorized):
+		return codes.Unauthenticated, "UNAUTHORIZED"
+	case errors.Is(err, ErrForbidden):
+		return codes.PermissionDenied, "FORBIDDEN"
+	case errors.Is(err, ErrConflict):
+		return codes.AlreadyExists, "CONFLICT"
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, "TIMEOUT"
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, ""
+	case errors.Is(err, ErrRateLimitExceeded):
+		return codes.ResourceExhausted, "RATE_LIMIT_EXCEEDED"
+	case errors.Is(err, ErrHandlerPanic):
+		return codes.Internal, ""
+	case errors.As(err, &remoteErr):
+		return codeFromHTTPStatus(remoteErr.Code), ""
+	}
+	return codes.Unknown, ""
+}
+
+func codeFromHTTPStatus(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if code >= 500 {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// ErrorUnaryServerInterceptor aplica GrpcStatus a los errores de los
+// handlers; GrpcServer lo instala siempre.
+func ErrorUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, GrpcStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+func ErrorStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return GrpcStatus(err).Err()
+		}
+		return nil
+	}
+}
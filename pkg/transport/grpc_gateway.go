@@ -0,0 +1,484 @@
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GatewayRoute expone un método unario de gRPC como endpoint REST.
+type GatewayRoute struct {
+	Method string `json:"method"`
+	// Path usa la sintaxis de google.api.http: /v1/users/{id}, {name=**}.
+	Path       string `json:"path"`
+	GrpcMethod string `json:"grpc_method"` // /paquete.Servicio/Metodo
+	// Body es "*" para mapear el cuerpo completo, el nombre de un campo, o
+	// vacío para leer solo path y query.
+	Body         string `json:"body,omitempty"`
+	ResponseBody string `json:"response_body,omitempty"`
+}
+
+// Gateway traduce peticiones HTTP/JSON a llamadas gRPC sobre los servicios
+// de un GrpcServer, usando los descriptores registrados en protoregistry.
+type Gateway struct {
+	server *GrpcServer
+	files  *protoregistry.Files
+	routes []GatewayRoute
+
+	mu   sync.Mutex
+	conn grpc.ClientConnInterface
+	own  *grpc.ClientConn
+}
+
+// NewGateway llama al servidor por loopback, de modo que las peticiones pasan
+// por los mismos middlewares que las llamadas gRPC. Las rutas declaradas con
+// google.api.http en los servicios registrados se montan automáticamente.
+func NewGateway(server *GrpcServer) *Gateway {
+	return &Gateway{server: server, files: protoregistry.GlobalFiles}
+}
+
+// NewGatewayWithConn expone servicios remotos a través de conn; solo se
+// montan las rutas agregadas con AddRoute o AddService.
+func NewGatewayWithConn(conn grpc.ClientConnInterface) *Gateway {
+	return &Gateway{conn: conn, files: protoregistry.GlobalFiles}
+}
+
+// AddRoute agrega una ruta manual. Sin Body, POST, PUT y PATCH mapean el
+// cuerpo completo.
+func (g *Gateway) AddRoute(route GatewayRoute) {
+	route.Method = strings.ToUpper(route.Method)
+	if route.Body == "" && (route.Method == http.MethodPost || route.Method == http.MethodPut || route.Method == http.MethodPatch) {
+		route.Body = "*"
+	}
+	g.routes = append(g.routes, route)
+}
+
+// AddService agrega las rutas anotadas con google.api.http del servicio.
+func (g *Gateway) AddService(serviceName string) error {
+	desc, err := g.files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return err
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return fmt.Errorf("gateway: %s is not a service", serviceName)
+	}
+	g.routes = append(g.routes, annotatedRoutes(service)...)
+	return nil
+}
+
+// Mount registra las rutas en router; handlers (guards, interceptores) se
+// ejecutan antes de cada llamada.
+func (g *Gateway) Mount(router gin.IRoutes, handlers ...gin.HandlerFunc) error {
+	routes := g.routes
+	if g.server != nil {
+		for name := range g.server.server.GetServiceInfo() {
+			desc, err := g.files.FindDescriptorByName(protoreflect.FullName(name))
+			if err != nil {
+				continue
+			}
+			if service, ok := desc.(protoreflect.ServiceDescriptor); ok {
+				routes = append(routes, annotatedRoutes(service)...)
+			}
+		}
+	}
+
+	// Un servicio agregado con AddService también aparece entre los del
+	// servidor: sus rutas se montan una sola vez.
+	mounted := make(map[string]string)
+	for _, route := range routes {
+		handler, ginPath, err := g.routeHandler(route)
+		if err != nil {
+			return err
+		}
+		key := route.Method + " " + ginPath
+		if grpcMethod, ok := mounted[key]; ok {
+			if grpcMethod != route.GrpcMethod {
+				return fmt.Errorf("gateway: %s is mapped to both %s and %s", key, grpcMethod, route.GrpcMethod)
+			}
+			continue
+		}
+		mounted[key] = route.GrpcMethod
+		chain := append(append([]gin.HandlerFunc{}, handlers...), handler)
+		router.Handle(route.Method, ginPath, chain...)
+	}
+	return nil
+}
+
+func (g *Gateway) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.own == nil {
+		return nil
+	}
+	err := g.own.Close()
+	g.own, g.conn = nil, nil
+	return err
+}
+
+func (g *Gateway) clientConn() (grpc.ClientConnInterface, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.conn != nil {
+		return g.conn, nil
+	}
+	addr := g.server.Addr()
+	if addr == nil {
+		return nil, status.Error(codes.Unavailable, "grpc server not started")
+	}
+	conn, err := DialGrpc(GrpcClientConfig{Target: addr.String()})
+	if err != nil {
+		return nil, err
+	}
+	g.conn, g.own = conn, conn
+	return conn, nil
+}
+
+func annotatedRoutes(service protoreflect.ServiceDescriptor) []GatewayRoute {
+	var routes []GatewayRoute
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		options, ok := method.Options().(*descriptorpb.MethodOptions)
+		if !ok || !proto.HasExtension(options, annotations.E_Http) {
+			continue
+		}
+		rule := proto.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+		fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+
+		for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+			if route, ok := ruleRoute(fullMethod, binding); ok {
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
+
+func ruleRoute(fullMethod string, rule *annotations.HttpRule) (GatewayRoute, bool) {
+	route := GatewayRoute{GrpcMethod: fullMethod, Body: rule.GetBody(), ResponseBody: rule.GetResponseBody()}
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		route.Method, route.Path = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Post:
+		route.Method, route.Path = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Put:
+		route.Method, route.Path = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Patch:
+		route.Method, route.Path = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Delete:
+		route.Method, route.Path = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Custom:
+		route.Method, route.Path = strings.ToUpper(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	default:
+		return route, false
+	}
+	return route, true
+}
+
+var templateVariable = regexp.MustCompile(`\{([^}=]+)(?:=([^}]*))?\}`)
+
+// compileTemplate convierte una plantilla de google.api.http en una ruta de
+// gin y devuelve el campo del request asociado a cada parámetro.
+func compileTemplate(template string) (string, map[string]string, error) {
+	if strings.Contains(templateVariable.ReplaceAllString(template, ""), ":") {
+		return "", nil, fmt.Errorf("gateway: custom verbs are not supported in %q", template)
+	}
+
+	params := make(map[string]string)
+	var compileErr error
+	ginPath := templateVariable.ReplaceAllStringFunc(template, func(variable string) string {
+		match := templateVariable.FindStringSubmatch(variable)
+		field, pattern := match[1], match[2]
+		key := strings.ReplaceAll(field, ".", "_")
+		params[key] = field
+
+		switch pattern {
+		case "", "*":
+			return ":" + key
+		case "**":
+			return "*" + key
+		}
+		compileErr = fmt.Errorf("gateway: unsupported path pattern %q in %q", variable, template)
+		return variable
+	})
+	return ginPath, params, compileErr
+}
+
+func (g *Gateway) routeHandler(route GatewayRoute) (gin.HandlerFunc, string, error) {
+	method, err := g.findMethod(route.GrpcMethod)
+	if err != nil {
+		return nil, "", err
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, "", fmt.Errorf("gateway: streaming method %s is not supported", route.GrpcMethod)
+	}
+	ginPath, params, err := compileTemplate(route.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return func(c *gin.Context) {
+		req := dynamicpb.NewMessage(method.Input())
+		if err := bindRequest(c, req, route.Body, params); err != nil {
+			WriteGatewayError(c, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		conn, err := g.clientConn()
+		if err != nil {
+			WriteGatewayError(c, err)
+			return
+		}
+
+		resp := dynamicpb.NewMessage(method.Output())
+		if err := conn.Invoke(gatewayContext(c), route.GrpcMethod, req, resp); err != nil {
+			WriteGatewayError(c, err)
+			return
+		}
+
+		var out proto.Message = resp
+		if route.ResponseBody != "" {
+			if field := fieldByName(resp.Descriptor(), route.ResponseBody); field != nil && field.Kind() == protoreflect.MessageKind && !field.IsList() && !field.IsMap() {
+				out = resp.Get(field).Message().Interface()
+			}
+		}
+		data, err := protojson.Marshal(out)
+		if err != nil {
+			WriteGatewayError(c, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		c.Data(http.StatusOK, "application/json", data)
+	}, ginPath, nil
+}
+
+func (g *Gateway) findMethod(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("gateway: invalid grpc method %q", fullMethod)
+	}
+	desc, err := g.files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("gateway: %s: %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("gateway: %s is not a service", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("gateway: method %s not found", fullMethod)
+	}
+	return method, nil
+}
+
+// gatewayContext reenvía la autorización y el request ID de la petición HTTP
+// como metadata de la llamada gRPC.
+func gatewayContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, auth)
+	}
+	if requestID := c.GetHeader("X-Request-Id"); requestID != "" {
+		ctx = ContextWithRequestID(ctx, requestID)
+	}
+	return ctx
+}
+
+var errUnknownField = errors.New("unknown field")
+
+func bindRequest(c *gin.Context, req *dynamicpb.Message, body string, params map[string]string) error {
+	if body != "" {
+		data, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		if len(data) > 0 {
+			target := req.ProtoReflect()
+			if body != "*" {
+				field := fieldByName(req.Descriptor(), body)
+				if field == nil || field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() {
+					return fmt.Errorf("body field %q must be a message", body)
+				}
+				target = req.Mutable(field).Message()
+			}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, target.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, field := range params {
+		if err := setField(req, field, strings.TrimPrefix(c.Param(key), "/")); err != nil {
+			return err
+		}
+	}
+
+	if body == "*" {
+		return nil
+	}
+	for key, values := range c.Request.URL.Query() {
+		for _, value := range values {
+			if err := setField(req, key, value); err != nil && !errors.Is(err, errUnknownField) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldByName(desc protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if field := desc.Fields().ByName(protoreflect.Name(name)); field != nil {
+		return field
+	}
+	return desc.Fields().ByJSONName(name)
+}
+
+// setField asigna value al campo indicado por una ruta con puntos
+// (user.address.city), creando los mensajes intermedios.
+func setField(msg protoreflect.Message, path string, value string) error {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		field := fieldByName(msg.Descriptor(), part)
+		if field == nil {
+			return fmt.Errorf("%w: %s", errUnknownField, path)
+		}
+		if i < len(parts)-1 {
+			if field.Kind() != protoreflect.MessageKind || field.IsList() || field.IsMap() {
+				return fmt.Errorf("field %s is not a message", part)
+			}
+			msg = msg.Mutable(field).Message()
+			continue
+		}
+
+		if field.IsMap() {
+			return fmt.Errorf("map field %s cannot be set from the url", path)
+		}
+		v, err := parseScalar(field, value)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+		if field.IsList() {
+			msg.Mutable(field).List().Append(v)
+		} else {
+			msg.Set(field, v)
+		}
+	}
+	return nil
+}
+
+func parseScalar(field protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(value)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByName(protoreflect.Name(value)); enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number()), nil
+		}
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %s", field.Kind())
+}
+
+// HTTPStatusFromCode sigue la correspondencia de grpc-gateway entre códigos
+// gRPC y estados HTTP.
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func WriteGatewayError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	body := gin.H{
+		"error": st.Message(),
+		"code":  st.Code().String(),
+	}
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			body["reason"] = detail.GetReason()
+		case *errdetails.BadRequest:
+			violations := make([]FieldViolation, len(detail.GetFieldViolations()))
+			for i, violation := range detail.GetFieldViolations() {
+				violations[i] = FieldViolation{Field: violation.GetField(), Description: violation.GetDescription()}
+			}
+			body["violations"] = violations
+		}
+	}
+	c.AbortWithStatusJSON(HTTPStatusFromCode(st.Code()), body)
+}
@@ -0,0 +1,150 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGatewayRouteTableAndErrorMapping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	runGrpcServer(t, server)
+
+	var guarded bool
+	gateway := NewGateway(server)
+	defer gateway.Close()
+	gateway.AddRoute(GatewayRoute{Method: "get", Path: "/v1/health/{service}", GrpcMethod: "/grpc.health.v1.Health/Check"})
+	gateway.AddRoute(GatewayRoute{Method: "GET", Path: "/v1/health", GrpcMethod: "/grpc.health.v1.Health/Check"})
+
+	router := gin.New()
+	if err := gateway.Mount(router, func(c *gin.Context) { guarded = true }); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != `{"status":"SERVING"}` {
+		t.Fatalf("unexpected response %d %s", recorder.Code, recorder.Body.String())
+	}
+	if !guarded {
+		t.Fatal("expected mount handlers to run")
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/health/unknown.Service", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for NOT_FOUND, got %d %s", recorder.Code, recorder.Body.String())
+	}
+	var body map[string]string
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+	if body["code"] != "NotFound" {
+		t.Fatalf("unexpected error body %v", body)
+	}
+}
+
+// usersFile describe users.Users/Save con dos bindings de google.api.http.
+func usersFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+
+	options := &descriptorpb.MethodOptions{}
+	proto.SetExtension(options, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/v1/users/{user.id}"},
+		Body:    "user",
+		AdditionalBindings: []*annotations.HttpRule{
+			{Pattern: &annotations.HttpRule_Put{Put: "/v1/users/{name=**}"}, Body: "*"},
+		},
+	})
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("users.proto"),
+		Package: proto.String("users"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Request")},
+			{Name: proto.String("Response")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Users"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Save"),
+				InputType:  proto.String(".users.Request"),
+				OutputType: proto.String(".users.Response"),
+				Options:    options,
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+	return file
+}
+
+func TestGatewayAnnotatedRoutes(t *testing.T) {
+	file := usersFile(t)
+	routes := annotatedRoutes(file.Services().Get(0))
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+	if routes[0].Method != http.MethodPost || routes[0].GrpcMethod != "/users.Users/Save" || routes[0].Body != "user" {
+		t.Fatalf("unexpected route %+v", routes[0])
+	}
+
+	ginPath, params, err := compileTemplate(routes[0].Path)
+	if err != nil || ginPath != "/v1/users/:user_id" || params["user_id"] != "user.id" {
+		t.Fatalf("unexpected template %q %v %v", ginPath, params, err)
+	}
+	if ginPath, _, err := compileTemplate(routes[1].Path); err != nil || ginPath != "/v1/users/*name" {
+		t.Fatalf("unexpected template %q %v", ginPath, err)
+	}
+	if _, _, err := compileTemplate("/v1/users/{id}:cancel"); err == nil {
+		t.Fatal("expected error for custom verb")
+	}
+}
+
+func TestGatewayMountsAddedServiceOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	files := new(protoregistry.Files)
+	if err := files.RegisterFile(usersFile(t)); err != nil {
+		t.Fatalf("RegisterFile: %v", err)
+	}
+
+	// users.Users está registrado en el servidor y además se agrega con
+	// AddService, así que sus rutas aparecen dos veces.
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	server.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "users.Users",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Save",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				return nil, nil
+			},
+		}},
+	}, struct{}{})
+
+	gateway := NewGateway(server)
+	gateway.files = files
+	if err := gateway.AddService("users.Users"); err != nil {
+		t.Fatalf("AddService: %v", err)
+	}
+
+	router := gin.New()
+	if err := gateway.Mount(router); err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	if routes := router.Routes(); len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %v", routes)
+	}
+}
@@ -0,0 +1,63 @@
+package transport
+
+import (
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// OverallService es el nombre con el que grpc.health.v1 (y las sondas gRPC de
+// Kubernetes) consultan el estado del servidor completo.
+const OverallService = ""
+
+// SetServingStatus actualiza el estado publicado para un servicio; usar
+// OverallService para el estado global.
+func (s *GrpcServer) SetServingStatus(service string, serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	s.health.SetServingStatus(service, status)
+}
+
+// SetServing actualiza a la vez el estado global y el de todos los servicios
+// registrados, para que quien consulte un servicio concreto vea también los
+// fallos de las dependencias.
+func (s *GrpcServer) SetServing(serving bool) {
+	s.SetServingStatus(OverallService, serving)
+	for name := range s.server.GetServiceInfo() {
+		if name == healthpb.Health_ServiceDesc.ServiceName {
+			continue
+		}
+		s.SetServingStatus(name, serving)
+	}
+}
+
+// SetHealthCheck indica cómo calcular el estado global al arrancar, para no
+// publicar SERVING antes de comprobar las dependencias.
+func (s *GrpcServer) SetHealthCheck(check func() bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthCheck = check
+}
+
+// markServing publica el estado global y el de todos los servicios
+// registrados según el health check; sin él, todos quedan SERVING.
+func (s *GrpcServer) markServing() {
+	s.mu.RLock()
+	check := s.healthCheck
+	s.mu.RUnlock()
+	s.SetServing(check == nil || check())
+}
+
+// Drain marca todos los servicios como NOT_SERVING sin cortar las conexiones,
+// para que los balanceadores dejen de enviar tráfico antes de Stop. Los
+// cambios de estado posteriores se ignoran.
+func (s *GrpcServer) Drain() {
+	s.health.Shutdown()
+}
+
+func newHealthServer() *health.Server {
+	server := health.NewServer()
+	server.SetServingStatus(OverallService, healthpb.HealthCheckResponse_NOT_SERVING)
+	return server
+}
@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+)
+
+type GrpcServerOptions struct {
+	// Address tiene prioridad sobre Port, por ejemplo "127.0.0.1:50051".
+	Address string
+	Port    string
+
+	// Keepalive que aplica el servidor a sus conexiones.
+	KeepaliveTime         time.Duration
+	KeepaliveTimeout      time.Duration
+	MaxConnectionIdle     time.Duration
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+
+	// Política que se exige a los pings de los clientes; los que la
+	// incumplen se desconectan.
+	KeepaliveMinTime             time.Duration
+	KeepalivePermitWithoutStream bool
+
+	MaxRecvMsgSize       int
+	MaxSendMsgSize       int
+	MaxConcurrentStreams uint32
+	ConnectionTimeout    time.Duration
+
+	// Compression es el compresor usado en las respuestas ("gzip") cuando el
+	// cliente lo admite.
+	Compression string
+
+	// ServerOptions se agregan tal cual al final, para lo que no cubren los
+	// campos anteriores.
+	ServerOptions []grpc.ServerOption
+}
+
+func DefaultGrpcServerOptions() GrpcServerOptions {
+	return GrpcServerOptions{Port: "50051"}
+}
+
+func (o GrpcServerOptions) address() string {
+	if o.Address != "" {
+		return o.Address
+	}
+	return ":" + o.Port
+}
+
+func (o GrpcServerOptions) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+
+	params := keepalive.ServerParameters{
+		Time:                  o.KeepaliveTime,
+		Timeout:               o.KeepaliveTimeout,
+		MaxConnectionIdle:     o.MaxConnectionIdle,
+		MaxConnectionAge:      o.MaxConnectionAge,
+		MaxConnectionAgeGrace: o.MaxConnectionAgeGrace,
+	}
+	if params != (keepalive.ServerParameters{}) {
+		opts = append(opts, grpc.KeepaliveParams(params))
+	}
+	if o.KeepaliveMinTime > 0 || o.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             o.KeepaliveMinTime,
+			PermitWithoutStream: o.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	if o.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(o.MaxRecvMsgSize))
+	}
+	if o.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(o.MaxSendMsgSize))
+	}
+	if o.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(o.MaxConcurrentStreams))
+	}
+	if o.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(o.ConnectionTimeout))
+	}
+
+	if o.Compression != "" {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				// Falla si el cliente no admite el compresor; se responde sin comprimir.
+				grpc.SetSendCompressor(ctx, o.Compression)
+				return handler(ctx, req)
+			}),
+			grpc.ChainStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+				grpc.SetSendCompressor(ss.Context(), o.Compression)
+				return handler(srv, ss)
+			}),
+		)
+	}
+
+	return append(opts, o.ServerOptions...)
+}
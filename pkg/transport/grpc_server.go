@@ -3,15 +3,28 @@ package transport
 import (
 	"context"
 	"net"
+	"sync"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 type GrpcServer struct {
-	server   *grpc.Server
-	listener net.Listener
-	port     string
+	server      *grpc.Server
+	listener    net.Listener
+	options     GrpcServerOptions
+	health      *health.Server
+	middlewares []Middleware
+	// unary y stream son la cadena de middlewares, construida una vez en
+	// Serve.
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+	// healthCheck decide el estado global inicial al arrancar; sin él se
+	// publica SERVING.
+	healthCheck func() bool
+	mu          sync.RWMutex
 }
 
 type GrpcService interface {
@@ -19,10 +32,50 @@ type GrpcService interface {
 }
 
 func NewGrpcServer(port string) *GrpcServer {
-	return &GrpcServer{
-		server: grpc.NewServer(),
-		port:   port,
-	}
+	options := DefaultGrpcServerOptions()
+	options.Port = port
+	return NewGrpcServerWithOptions(options)
+}
+
+func NewGrpcServerWithOptions(options GrpcServerOptions) *GrpcServer {
+	s := &GrpcServer{options: options, health: newHealthServer()}
+	serverOptions := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(ErrorUnaryServerInterceptor(), s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(ErrorStreamServerInterceptor(), s.streamInterceptor),
+	}, options.serverOptions()...)
+	s.server = grpc.NewServer(serverOptions...)
+	healthpb.RegisterHealthServer(s.server, s.health)
+	return s
+}
+
+// Use agrega middlewares de transporte a todas las llamadas unarias y de
+// streaming; debe llamarse antes de Start.
+func (s *GrpcServer) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+}
+
+func (s *GrpcServer) buildInterceptors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	middlewares := append([]Middleware{RecoveryMiddleware()}, s.middlewares...)
+	s.unary = UnaryServerInterceptor(middlewares...)
+	s.stream = StreamServerInterceptor(middlewares...)
+}
+
+func (s *GrpcServer) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	s.mu.RLock()
+	unary := s.unary
+	s.mu.RUnlock()
+	return unary(ctx, req, info, handler)
+}
+
+func (s *GrpcServer) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s.mu.RLock()
+	stream := s.stream
+	s.mu.RUnlock()
+	return stream(srv, ss, info, handler)
 }
 
 func (s *GrpcServer) RegisterService(service GrpcService) {
@@ -34,25 +87,53 @@ func (s *GrpcServer) EnableReflection() {
 }
 
 func (s *GrpcServer) Start() error {
-	lis, err := net.Listen("tcp", ":"+s.port)
+	lis, err := net.Listen("tcp", s.options.address())
 	if err != nil {
 		return err
 	}
+	return s.Serve(lis)
+}
+
+// Serve atiende sobre un listener ya abierto (por ejemplo en tests o con
+// sockets heredados).
+func (s *GrpcServer) Serve(lis net.Listener) error {
+	s.buildInterceptors()
+	s.mu.Lock()
 	s.listener = lis
+	s.mu.Unlock()
 
+	s.markServing()
 	return s.server.Serve(lis)
 }
 
+// Addr devuelve la dirección en la que escucha el servidor, o nil si aún no
+// se ha iniciado.
+func (s *GrpcServer) Addr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
 func (s *GrpcServer) Stop() {
+	s.Drain()
 	s.server.GracefulStop()
 }
 
 func (s *GrpcServer) StopNow() {
+	s.Drain()
 	s.server.Stop()
 }
 
 type BaseGrpcService struct{}
 
+// HandleError traduce los errores del framework (ErrNotFound,
+// ValidationError, ...) a su status de gRPC con los detalles de errdetails.
 func (b *BaseGrpcService) HandleError(ctx context.Context, err error) error {
-	return err
-}
\ No newline at end of file
+	if err == nil {
+		return nil
+	}
+	return GrpcStatus(err).Err()
+}
@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func runGrpcServer(t *testing.T, server *GrpcServer) *grpc.ClientConn {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go server.Serve(lis)
+	t.Cleanup(server.StopNow)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGrpcServerMaxRecvMsgSize(t *testing.T) {
+	options := DefaultGrpcServerOptions()
+	options.MaxRecvMsgSize = 64
+	options.Compression = "gzip"
+	server := NewGrpcServerWithOptions(options)
+
+	client := healthpb.NewHealthClient(runGrpcServer(t, server))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: strings.Repeat("x", 128)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+}
+
+func TestGrpcServerHealthFollowsLifecycle(t *testing.T) {
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	client := healthpb.NewHealthClient(runGrpcServer(t, server))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", service, err)
+		}
+		return resp.Status
+	}
+
+	if got := check(OverallService); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING after start, got %v", got)
+	}
+
+	server.SetServingStatus(OverallService, false)
+	if got := check(OverallService); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING, got %v", got)
+	}
+	server.SetServingStatus(OverallService, true)
+
+	server.Drain()
+	server.SetServingStatus(OverallService, true)
+	if got := check(OverallService); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING while draining, got %v", got)
+	}
+}
+
+func TestGrpcServerMapsFrameworkErrors(t *testing.T) {
+	var failure error
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	server.Use(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			return nil, failure
+		}
+	})
+	client := healthpb.NewHealthClient(runGrpcServer(t, server))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	failure = fmt.Errorf("user 42: %w", ErrNotFound)
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound || st.Message() != "user 42: not found" {
+		t.Fatalf("unexpected status %v", st)
+	}
+	if info, ok := st.Details()[0].(*errdetails.ErrorInfo); !ok || info.Reason != "NOT_FOUND" || info.Domain != ErrorDomain {
+		t.Fatalf("unexpected details %v", st.Details())
+	}
+
+	failure = NewValidationError(FieldViolation{Field: "email", Description: "required"})
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	st = status.Convert(err)
+	if st.Code() != codes.InvalidArgument || len(st.Details()) != 2 {
+		t.Fatalf("unexpected status %v", st)
+	}
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	if !ok || badRequest.FieldViolations[0].Field != "email" {
+		t.Fatalf("unexpected details %v", st.Details())
+	}
+
+	failure = status.Error(codes.Unavailable, "maintenance")
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected status errors to pass through, got %v", err)
+	}
+}
+
+func TestGrpcServerRecoversFromHandlerPanic(t *testing.T) {
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	// Servicio mínimo cuyo handler entra en pánico, con los mensajes de
+	// health para no depender de código generado.
+	server.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Panics",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Boom",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(healthpb.HealthCheckRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) { panic("boom") }
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Panics/Boom"}, handler)
+			},
+		}},
+	}, struct{}{})
+	conn := runGrpcServer(t, server)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := conn.Invoke(ctx, "/test.Panics/Boom", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != ErrHandlerPanic.Error() {
+		t.Fatalf("unexpected status %v", st)
+	}
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("server unusable after panic: %v", err)
+	}
+}
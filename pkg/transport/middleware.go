@@ -0,0 +1,262 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Endpoint es la forma común de una llamada en cualquier transporte: en TCP
+// y NATS el request y la respuesta son []byte, en gRPC son los mensajes
+// protobuf (o el grpc.ServerStream en llamadas de streaming).
+type Endpoint func(ctx context.Context, request interface{}) (interface{}, error)
+
+type Middleware func(Endpoint) Endpoint
+
+var (
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrHandlerPanic      = errors.New("internal server error")
+)
+
+// DefaultMetrics recibe los panics recuperados por los transportes;
+// puede pasarse también a MetricsMiddleware para centralizar las métricas.
+var DefaultMetrics = NewMetrics()
+
+// Chain compone los middlewares; el primero es el más externo.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Endpoint) Endpoint {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+func handlerEndpoint(handler ContextHandler) Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		data, _ := request.([]byte)
+		return handler(ctx, data)
+	}
+}
+
+func responseBytes(response interface{}) []byte {
+	data, _ := response.([]byte)
+	return data
+}
+
+func operationName(ctx context.Context) string {
+	md, ok := MetadataFromContext(ctx)
+	if !ok {
+		return "unknown"
+	}
+	return md.Transport + " " + md.Operation
+}
+
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			start := time.Now()
+			operation := operationName(ctx)
+
+			logger.Printf("[REQUEST] %s", operation)
+			response, err := next(ctx, request)
+			if err != nil {
+				logger.Printf("[RESPONSE] %s - Error: %v - Duration: %v", operation, err, time.Since(start))
+			} else {
+				logger.Printf("[RESPONSE] %s - Duration: %v", operation, time.Since(start))
+			}
+			return response, err
+		}
+	}
+}
+
+// AuthMiddleware ejecuta authenticate antes del handler y deja la identidad
+// devuelta disponible mediante IdentityFromContext.
+func AuthMiddleware(authenticate func(ctx context.Context) (interface{}, error)) Middleware {
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			identity, err := authenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return next(ContextWithIdentity(ctx, identity), request)
+		}
+	}
+}
+
+// RateLimitMiddleware limita las llamadas por operación y cliente dentro de
+// una ventana fija de tiempo.
+func RateLimitMiddleware(limit int, window time.Duration) Middleware {
+	var mu sync.Mutex
+	counters := make(map[string]int)
+	resetAt := time.Now().Add(window)
+
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key := operationName(ctx)
+			if md, ok := MetadataFromContext(ctx); ok && md.RemoteAddr != nil {
+				key += " " + md.RemoteAddr.String()
+			}
+
+			mu.Lock()
+			if time.Now().After(resetAt) {
+				counters = make(map[string]int)
+				resetAt = time.Now().Add(window)
+			}
+			counters[key]++
+			exceeded := counters[key] > limit
+			mu.Unlock()
+
+			if exceeded {
+				return nil, ErrRateLimitExceeded
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// RecoveryMiddleware convierte un panic del handler en ErrHandlerPanic,
+// registra el stack y cuenta el panic en DefaultMetrics. TcpServer,
+// NatsClient y GrpcServer la aplican siempre como middleware más externo.
+func RecoveryMiddleware() Middleware {
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					operation := operationName(ctx)
+					log.Printf("[PANIC] %s: %v\n%s", operation, r, debug.Stack())
+					DefaultMetrics.recordPanic(operation)
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+			return next(ctx, request)
+		}
+	}
+}
+
+func withRecovery(middlewares []Middleware) Middleware {
+	return Chain(append([]Middleware{RecoveryMiddleware()}, middlewares...)...)
+}
+
+type OperationStats struct {
+	Requests      int64
+	Errors        int64
+	Panics        int64
+	TotalDuration time.Duration
+}
+
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*OperationStats
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*OperationStats)}
+}
+
+func (m *Metrics) operation(operation string) *OperationStats {
+	stats, exists := m.stats[operation]
+	if !exists {
+		stats = &OperationStats{}
+		m.stats[operation] = stats
+	}
+	return stats
+}
+
+func (m *Metrics) record(operation string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.operation(operation)
+	stats.Requests++
+	stats.TotalDuration += duration
+	if err != nil {
+		stats.Errors++
+	}
+}
+
+func (m *Metrics) recordPanic(operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.operation(operation).Panics++
+}
+
+func (m *Metrics) Snapshot() map[string]OperationStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]OperationStats, len(m.stats))
+	for operation, stats := range m.stats {
+		snapshot[operation] = *stats
+	}
+	return snapshot
+}
+
+func MetricsMiddleware(metrics *Metrics) Middleware {
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			start := time.Now()
+			response, err := next(ctx, request)
+			metrics.record(operationName(ctx), time.Since(start), err)
+			return response, err
+		}
+	}
+}
+
+func grpcMetadata(ctx context.Context, fullMethod string) *Metadata {
+	md := &Metadata{
+		Transport: "grpc",
+		Operation: fullMethod,
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		md.RemoteAddr = p.Addr
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state := tls.ConnectionState(info.State)
+			md.TLS = &state
+		}
+	}
+	return md
+}
+
+// UnaryServerInterceptor adapta la cadena de middlewares a un interceptor
+// unario de gRPC.
+func UnaryServerInterceptor(middlewares ...Middleware) grpc.UnaryServerInterceptor {
+	chain := Chain(middlewares...)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = ContextWithMetadata(ctx, grpcMetadata(ctx, info.FullMethod))
+		return chain(Endpoint(handler))(ctx, req)
+	}
+}
+
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedServerStream) Context() context.Context {
+	return w.ctx
+}
+
+// StreamServerInterceptor adapta la cadena de middlewares a un interceptor
+// de streaming; el request que reciben los middlewares es el grpc.ServerStream.
+func StreamServerInterceptor(middlewares ...Middleware) grpc.StreamServerInterceptor {
+	chain := Chain(middlewares...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ContextWithMetadata(ss.Context(), grpcMetadata(ss.Context(), info.FullMethod))
+		endpoint := chain(func(ctx context.Context, request interface{}) (interface{}, error) {
+			stream := request.(grpc.ServerStream)
+			return nil, handler(srv, &wrappedServerStream{ServerStream: stream, ctx: ctx})
+		})
+		_, err := endpoint(ctx, ss)
+		return err
+	}
+}
@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			*calls = append(*calls, name+" in")
+			response, err := next(ctx, request)
+			*calls = append(*calls, name+" out")
+			return response, err
+		}
+	}
+}
+
+func echoEndpoint(ctx context.Context, request interface{}) (interface{}, error) {
+	return request, nil
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	endpoint := Chain(recordingMiddleware("a", &calls), recordingMiddleware("b", &calls))(
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+			return request, nil
+		})
+
+	if _, err := endpoint(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a in", "b in", "handler", "b out", "a out"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestAuthMiddlewareShortCircuits(t *testing.T) {
+	denied := errors.New("denied")
+	called := false
+	endpoint := AuthMiddleware(func(ctx context.Context) (interface{}, error) {
+		return nil, denied
+	})(func(ctx context.Context, request interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+	if _, err := endpoint(context.Background(), nil); !errors.Is(err, denied) {
+		t.Fatalf("expected denied, got %v", err)
+	}
+	if called {
+		t.Fatal("handler called after failed authentication")
+	}
+
+	endpoint = AuthMiddleware(func(ctx context.Context) (interface{}, error) {
+		return "user-1", nil
+	})(func(ctx context.Context, request interface{}) (interface{}, error) {
+		identity, _ := IdentityFromContext(ctx)
+		return identity, nil
+	})
+	if identity, _ := endpoint(context.Background(), nil); identity != "user-1" {
+		t.Fatalf("identity = %v, want user-1", identity)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	endpoint := RateLimitMiddleware(2, time.Hour)(echoEndpoint)
+	ctx := ContextWithMetadata(context.Background(), &Metadata{Transport: "tcp", Operation: "users.get"})
+	other := ContextWithMetadata(context.Background(), &Metadata{Transport: "tcp", Operation: "users.list"})
+
+	for i := 0; i < 2; i++ {
+		if _, err := endpoint(ctx, nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if _, err := endpoint(ctx, nil); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected ErrRateLimitExceeded, got %v", err)
+	}
+	if _, err := endpoint(other, nil); err != nil {
+		t.Fatalf("limit should be per operation: %v", err)
+	}
+}
+
+func TestLoggingAndMetricsMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	metrics := NewMetrics()
+	failure := errors.New("boom")
+	endpoint := Chain(LoggingMiddleware(log.New(&buf, "", 0)), MetricsMiddleware(metrics))(
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			if request == "fail" {
+				return nil, failure
+			}
+			return request, nil
+		})
+	ctx := ContextWithMetadata(context.Background(), &Metadata{Transport: "nats", Operation: "orders.created"})
+
+	endpoint(ctx, "ok")
+	endpoint(ctx, "fail")
+
+	stats := metrics.Snapshot()["nats orders.created"]
+	if stats.Requests != 2 || stats.Errors != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if out := buf.String(); !strings.Contains(out, "[REQUEST] nats orders.created") || !strings.Contains(out, "Error: boom") {
+		t.Fatalf("unexpected log:\n%s", out)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var operation string
+	interceptor := UnaryServerInterceptor(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			md, _ := MetadataFromContext(ctx)
+			operation = md.Transport + " " + md.Operation
+			return next(ctx, request)
+		}
+	})
+
+	response, err := interceptor(context.Background(), "ping", &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil })
+	if err != nil || response != "ping" {
+		t.Fatalf("response = %v, %v", response, err)
+	}
+	if operation != "grpc /users.Users/Get" {
+		t.Fatalf("operation = %q", operation)
+	}
+
+	denied := errors.New("denied")
+	short := UnaryServerInterceptor(AuthMiddleware(func(ctx context.Context) (interface{}, error) { return nil, denied }))
+	_, err = short(context.Background(), "ping", &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			t.Fatal("handler called after failed authentication")
+			return nil, nil
+		})
+	if !errors.Is(err, denied) {
+		t.Fatalf("expected denied, got %v", err)
+	}
+}
+
+func TestGrpcServerAppliesMiddlewaresToStreams(t *testing.T) {
+	var mu sync.Mutex
+	var operations []string
+	server := NewGrpcServerWithOptions(DefaultGrpcServerOptions())
+	server.Use(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			md, _ := MetadataFromContext(ctx)
+			mu.Lock()
+			operations = append(operations, md.Operation)
+			mu.Unlock()
+			return next(ctx, request)
+		}
+	})
+	client := healthpb.NewHealthClient(runGrpcServer(t, server))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if _, err := watch.Recv(); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"/grpc.health.v1.Health/Check", "/grpc.health.v1.Health/Watch"}
+	if !reflect.DeepEqual(operations, want) {
+		t.Fatalf("operations = %v, want %v", operations, want)
+	}
+}
+
+func TestStreamServerInterceptorPassesContext(t *testing.T) {
+	interceptor := StreamServerInterceptor(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			return next(ContextWithIdentity(ctx, "streamer"), request)
+		}
+	})
+
+	var identity interface{}
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/chat.Chat/Join"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			identity, _ = IdentityFromContext(stream.Context())
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity != "streamer" {
+		t.Fatalf("identity = %v, want streamer", identity)
+	}
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context { return f.ctx }
@@ -1,39 +1,60 @@
 package transport
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
 )
 
 type NatsClient struct {
-	conn *nats.Conn
-	url  string
+	conn        *nats.Conn
+	options     NatsOptions
+	codec       Codec
+	events      chan ConnectionEvent
+	closed      chan struct{}
+	middlewares []Middleware
+	mu          sync.Mutex
+	ctx         context.Context
+	cancel      context.CancelFunc
 }
 
 type NatsHandler func([]byte) ([]byte, error)
 
 type NatsSubscription struct {
 	subscription *nats.Subscription
+	cancel       context.CancelFunc
+	done         chan struct{}
 }
 
 func NewNatsClient(url string) *NatsClient {
-	return &NatsClient{url: url}
+	options := DefaultNatsOptions()
+	options.Servers = []string{url}
+	return NewNatsClientWithOptions(options)
+}
+
+func NewNatsClientWithOptions(options NatsOptions) *NatsClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &NatsClient{
+		options: options,
+		codec:   JSONCodec,
+		events:  make(chan ConnectionEvent, 16),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
 }
 
 func (c *NatsClient) Connect() error {
-	conn, err := nats.Connect(c.url,
-		nats.ReconnectWait(time.Second*2),
-		nats.MaxReconnects(5),
-		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
-			fmt.Printf("NATS disconnected: %v\n", err)
-		}),
-		nats.ReconnectHandler(func(nc *nats.Conn) {
-			fmt.Printf("NATS reconnected to %v\n", nc.ConnectedUrl())
-		}),
-	)
+	c.closed = make(chan struct{})
+	opts, err := c.options.natsOptions(c)
+	if err != nil {
+		return err
+	}
+
+	conn, err := nats.Connect(c.options.url(), opts...)
 	if err != nil {
 		return err
 	}
@@ -41,62 +62,150 @@ func (c *NatsClient) Connect() error {
 	return nil
 }
 
+// Use agrega middlewares a las suscripciones creadas después de la llamada.
+func (c *NatsClient) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// endpoint aplica al handler la recuperación de panics y los middlewares
+// registrados hasta ahora.
+func (c *NatsClient) endpoint(handler ContextHandler) Endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return withRecovery(c.middlewares)(handlerEndpoint(handler))
+}
+
+// SetCodec cambia el codec usado por Publish y Request (JSON por defecto).
+func (c *NatsClient) SetCodec(codec Codec) {
+	c.codec = codec
+}
+
+func (c *NatsClient) newMsg(subject string, data interface{}) (*nats.Msg, error) {
+	payload, err := c.codec.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	msg := nats.NewMsg(subject)
+	msg.Header.Set(ContentTypeHeader, c.codec.ContentType())
+	msg.Data = payload
+	return msg, nil
+}
+
+// codecFor devuelve el codec indicado por la cabecera Content-Type del
+// mensaje o, si no la trae, el codec del cliente.
+func (c *NatsClient) codecFor(header nats.Header) (Codec, error) {
+	contentType := header.Get(ContentTypeHeader)
+	if contentType == "" {
+		return c.codec, nil
+	}
+	codec, ok := CodecFor(contentType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	return codec, nil
+}
+
 func (c *NatsClient) Publish(subject string, data interface{}) error {
-	jsonData, err := json.Marshal(data)
+	msg, err := c.newMsg(subject, data)
 	if err != nil {
 		return err
 	}
-	return c.conn.Publish(subject, jsonData)
+	return c.conn.PublishMsg(msg)
 }
 
+// Request devuelve la respuesta y, si el servicio respondió con un error,
+// un *RemoteError con su código.
 func (c *NatsClient) Request(subject string, data interface{}, timeout time.Duration) (*nats.Msg, error) {
-	jsonData, err := json.Marshal(data)
+	msg, err := c.newMsg(subject, data)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.conn.RequestMsg(msg, timeout)
 	if err != nil {
 		return nil, err
 	}
-	return c.conn.Request(subject, jsonData, timeout)
+	return response, decodeRemoteError(subject, response)
 }
 
 func (c *NatsClient) Subscribe(subject string, handler NatsHandler) (*NatsSubscription, error) {
-	sub, err := c.conn.Subscribe(subject, func(msg *nats.Msg) {
-		response, err := handler(msg.Data)
-		if err != nil {
-			fmt.Printf("Error handling message: %v\n", err)
-			return
-		}
-		if msg.Reply != "" {
-			c.conn.Publish(msg.Reply, response)
-		}
-	})
+	return c.SubscribeWithContext(subject, adaptHandler(handler))
+}
+
+func (c *NatsClient) QueueSubscribe(subject, queue string, handler NatsHandler) (*NatsSubscription, error) {
+	return c.QueueSubscribeWithContext(subject, queue, adaptHandler(handler))
+}
+
+func (c *NatsClient) SubscribeWithContext(subject string, handler ContextHandler) (*NatsSubscription, error) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	sub, err := c.conn.Subscribe(subject, c.messageHandler(ctx, handler))
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	return &NatsSubscription{subscription: sub}, nil
+	return &NatsSubscription{subscription: sub, cancel: cancel}, nil
 }
 
-func (c *NatsClient) QueueSubscribe(subject, queue string, handler NatsHandler) (*NatsSubscription, error) {
-	sub, err := c.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
-		response, err := handler(msg.Data)
+func (c *NatsClient) QueueSubscribeWithContext(subject, queue string, handler ContextHandler) (*NatsSubscription, error) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	sub, err := c.conn.QueueSubscribe(subject, queue, c.messageHandler(ctx, handler))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &NatsSubscription{subscription: sub, cancel: cancel}, nil
+}
+
+func (c *NatsClient) messageHandler(ctx context.Context, handler ContextHandler) nats.MsgHandler {
+	endpoint := c.endpoint(handler)
+	return func(msg *nats.Msg) {
+		md := &Metadata{
+			Transport: "nats",
+			Operation: msg.Subject,
+			Subject:   msg.Subject,
+			Reply:     msg.Reply,
+			Header:    msg.Header,
+		}
+		response, err := endpoint(ContextWithMetadata(ctx, md), msg.Data)
 		if err != nil {
-			fmt.Printf("Error handling message: %v\n", err)
+			log.Printf("Error handling message on %s: %v", msg.Subject, err)
+			if msg.Reply != "" {
+				c.conn.PublishMsg(newErrorReply(msg.Reply, err))
+			}
 			return
 		}
 		if msg.Reply != "" {
-			c.conn.Publish(msg.Reply, response)
+			reply := nats.NewMsg(msg.Reply)
+			if contentType := msg.Header.Get(ContentTypeHeader); contentType != "" {
+				reply.Header.Set(ContentTypeHeader, contentType)
+			}
+			reply.Data = responseBytes(response)
+			c.conn.PublishMsg(reply)
 		}
-	})
-	if err != nil {
-		return nil, err
 	}
-	return &NatsSubscription{subscription: sub}, nil
 }
 
 func (c *NatsClient) Close() {
-	if c.conn != nil {
-		c.conn.Close()
+	// El contexto de los handlers se cancela al final, para que los mensajes
+	// en curso terminen mientras se drena la conexión.
+	defer c.cancel()
+	if c.conn == nil {
+		return
+	}
+	if c.options.DrainOnClose && c.conn.IsConnected() {
+		if err := c.conn.Drain(); err == nil {
+			<-c.closed
+			return
+		}
 	}
+	c.conn.Close()
 }
 
 func (s *NatsSubscription) Unsubscribe() error {
+	s.cancel()
+	if s.done != nil {
+		<-s.done
+	}
 	return s.subscription.Unsubscribe()
-}
\ No newline at end of file
+}
@@ -0,0 +1,261 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type greetRequest struct {
+	Name string `json:"name" msgpack:"name"`
+}
+
+type greetResponse struct {
+	Message string `json:"message" msgpack:"message"`
+}
+
+func TestTypedRequestReplyWithCodecs(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec, CBORCodec} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			client := runNatsServer(t)
+			sub, err := Handle(client, "greet", func(ctx context.Context, req greetRequest) (greetResponse, error) {
+				return greetResponse{Message: "hola " + req.Name}, nil
+			})
+			if err != nil {
+				t.Fatalf("Handle: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			client.SetCodec(codec)
+			resp, err := RequestTyped[greetRequest, greetResponse](client, "greet", greetRequest{Name: "goney"}, 2*time.Second)
+			if err != nil {
+				t.Fatalf("RequestTyped: %v", err)
+			}
+			if resp.Message != "hola goney" {
+				t.Fatalf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
+
+func TestNatsClientEmitsConnectionEvents(t *testing.T) {
+	client := runNatsServer(t)
+	url := client.conn.ConnectedUrl()
+
+	options := DefaultNatsOptions()
+	options.Servers = []string{url}
+	options.DrainOnClose = true
+	drained := NewNatsClientWithOptions(options)
+	if err := drained.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := drained.HealthCheck(); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+
+	drained.Close()
+	if err := drained.HealthCheck(); !errors.Is(err, ErrNatsNotConnected) {
+		t.Fatalf("expected ErrNatsNotConnected, got %v", err)
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-drained.Events():
+			if event.State == ConnectionClosed {
+				return
+			}
+		case <-timeout:
+			t.Fatal("closed event not received")
+		}
+	}
+}
+
+func TestNatsClientCloseLetsInFlightHandlersFinish(t *testing.T) {
+	requester := runNatsServer(t)
+
+	options := DefaultNatsOptions()
+	options.Servers = []string{requester.conn.ConnectedUrl()}
+	options.DrainOnClose = true
+	client := NewNatsClientWithOptions(options)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	started := make(chan struct{})
+	_, err := client.SubscribeWithContext("slow", func(ctx context.Context, data []byte) ([]byte, error) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return []byte("done"), nil
+	})
+	if err != nil {
+		t.Fatalf("SubscribeWithContext: %v", err)
+	}
+	client.conn.Flush()
+
+	replies := make(chan *nats.Msg, 1)
+	go func() {
+		msg, _ := requester.conn.Request("slow", nil, 2*time.Second)
+		replies <- msg
+	}()
+	<-started
+	client.Close()
+
+	msg := <-replies
+	if msg == nil || string(msg.Data) != "done" {
+		t.Fatalf("in-flight handler did not finish successfully: %+v", msg)
+	}
+}
+
+func TestRequestManyGathersAllResponders(t *testing.T) {
+	client := runNatsServer(t)
+
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		sub, err := client.Subscribe("inventory.count", func(data []byte) ([]byte, error) {
+			return []byte(name), nil
+		})
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+		defer sub.Unsubscribe()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	responses, err := client.RequestMany(ctx, "inventory.count", nil, RequestManyOptions{})
+	if err != nil {
+		t.Fatalf("RequestMany: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(responses))
+	}
+
+	responses, err = client.RequestMany(context.Background(), "inventory.count", nil, RequestManyOptions{MaxMessages: 2})
+	if err != nil || len(responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d (%v)", len(responses), err)
+	}
+
+	missingCtx, missingCancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer missingCancel()
+	if _, err := client.RequestMany(missingCtx, "inventory.missing", nil, RequestManyOptions{}); !errors.Is(err, nats.ErrNoResponders) {
+		t.Fatalf("expected ErrNoResponders, got %v", err)
+	}
+}
+
+func TestRequestManyStopsOnOwnLimits(t *testing.T) {
+	client := runNatsServer(t)
+
+	for _, name := range []string{"ok", "failing"} {
+		name := name
+		sub, err := client.Subscribe("inventory.stock", func(data []byte) ([]byte, error) {
+			if name == "failing" {
+				return nil, NewServiceError(503, "warehouse offline")
+			}
+			return []byte(name), nil
+		})
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+		defer sub.Unsubscribe()
+	}
+
+	// Sin deadline ni límites se aplica DefaultRequestManyTimeout.
+	defer func(timeout time.Duration) { DefaultRequestManyTimeout = timeout }(DefaultRequestManyTimeout)
+	DefaultRequestManyTimeout = 300 * time.Millisecond
+	start := time.Now()
+	responses, err := client.RequestMany(context.Background(), "inventory.stock", nil, RequestManyOptions{})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("RequestMany took %v without a deadline", elapsed)
+	}
+	if len(responses) != 1 || string(responses[0].Data) != "ok" {
+		t.Fatalf("unexpected responses %v", responses)
+	}
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) || remoteErr.Code != 503 || remoteErr.Message != "warehouse offline" {
+		t.Fatalf("expected the remote error, got %v", err)
+	}
+
+	// El StallTimeout corta la espera sin error.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start = time.Now()
+	responses, err = client.RequestMany(ctx, "inventory.count.none", nil, RequestManyOptions{StallTimeout: 100 * time.Millisecond})
+	if !errors.Is(err, nats.ErrNoResponders) {
+		t.Fatalf("expected ErrNoResponders, got %v (%v)", err, responses)
+	}
+	sub, err := client.Subscribe("inventory.single", func(data []byte) ([]byte, error) { return []byte("one"), nil })
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+	responses, err = client.RequestMany(ctx, "inventory.single", nil, RequestManyOptions{StallTimeout: 100 * time.Millisecond})
+	if err != nil || len(responses) != 1 {
+		t.Fatalf("expected 1 response and no error, got %d (%v)", len(responses), err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("StallTimeout not applied, took %v", elapsed)
+	}
+}
+
+func TestRequestWithContextCancellation(t *testing.T) {
+	client := runNatsServer(t)
+
+	sub, err := client.Subscribe("slow", func(data []byte) ([]byte, error) {
+		time.Sleep(time.Second)
+		return data, nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := client.RequestWithContext(ctx, "slow", "ping"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("request was not cancelled promptly")
+	}
+}
+
+func TestNatsHandlerPanicRepliesWithError(t *testing.T) {
+	client := runNatsServer(t)
+
+	for subject, handler := range map[string]NatsHandler{
+		"boom": func(data []byte) ([]byte, error) { panic("boom") },
+		"echo": func(data []byte) ([]byte, error) { return data, nil },
+	} {
+		sub, err := client.Subscribe(subject, handler)
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+		defer sub.Unsubscribe()
+	}
+
+	_, err := client.Request("boom", nil, 2*time.Second)
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) {
+		t.Fatalf("expected RemoteError, got %v", err)
+	}
+	if remoteErr.Code != 500 || remoteErr.Message != ErrHandlerPanic.Error() {
+		t.Fatalf("unexpected remote error %+v", remoteErr)
+	}
+	if panics := DefaultMetrics.Snapshot()["nats boom"].Panics; panics == 0 {
+		t.Fatal("panic not recorded in DefaultMetrics")
+	}
+
+	response, err := client.Request("echo", "ping", 2*time.Second)
+	if err != nil || string(response.Data) != `"ping"` {
+		t.Fatalf("client unusable after panic: %v", err)
+	}
+}
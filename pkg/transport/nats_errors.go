@@ -0,0 +1,103 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Cabeceras usadas por el framework de servicios de NATS (micro) para
+// indicar que la respuesta es un error.
+const (
+	ServiceErrorHeader     = "Nats-Service-Error"
+	ServiceErrorCodeHeader = "Nats-Service-Error-Code"
+)
+
+type ErrorEnvelope struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+}
+
+// RemoteError es el error que devuelve Request cuando el servicio respondió
+// con un error. Los handlers pueden devolverlo (con NewServiceError) para
+// elegir el código enviado.
+type RemoteError struct {
+	Subject string
+	Code    int
+	Message string
+}
+
+func NewServiceError(code int, message string) *RemoteError {
+	return &RemoteError{Code: code, Message: message}
+}
+
+func (e *RemoteError) Error() string {
+	if e.Subject == "" {
+		return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("nats %s: %s (code %d)", e.Subject, e.Message, e.Code)
+}
+
+func errorCode(err error) int {
+	var remoteErr *RemoteError
+	switch {
+	case errors.As(err, &remoteErr):
+		return remoteErr.Code
+	case errors.Is(err, ErrRateLimitExceeded):
+		return http.StatusTooManyRequests
+	}
+	if code, reason := grpcCode(err); reason != "" {
+		return HTTPStatusFromCode(code)
+	}
+	return http.StatusInternalServerError
+}
+
+func errorMessage(err error) string {
+	var remoteErr *RemoteError
+	switch {
+	case errors.As(err, &remoteErr):
+		return remoteErr.Message
+	case errors.Is(err, ErrHandlerPanic):
+		return ErrHandlerPanic.Error()
+	default:
+		return err.Error()
+	}
+}
+
+func newErrorReply(reply string, err error) *nats.Msg {
+	envelope := ErrorEnvelope{Error: errorMessage(err), Code: errorCode(err)}
+
+	msg := nats.NewMsg(reply)
+	msg.Header.Set(ServiceErrorHeader, envelope.Error)
+	msg.Header.Set(ServiceErrorCodeHeader, strconv.Itoa(envelope.Code))
+	msg.Data, _ = json.Marshal(envelope)
+	return msg
+}
+
+// decodeRemoteError devuelve un *RemoteError si la respuesta trae las
+// cabeceras de error del servicio.
+func decodeRemoteError(subject string, msg *nats.Msg) error {
+	if msg == nil || msg.Header == nil {
+		return nil
+	}
+	codeHeader := msg.Header.Get(ServiceErrorCodeHeader)
+	if codeHeader == "" {
+		return nil
+	}
+
+	code, err := strconv.Atoi(codeHeader)
+	if err != nil {
+		code = http.StatusInternalServerError
+	}
+	message := msg.Header.Get(ServiceErrorHeader)
+
+	var envelope ErrorEnvelope
+	if json.Unmarshal(msg.Data, &envelope) == nil && envelope.Error != "" {
+		message = envelope.Error
+	}
+	return &RemoteError{Subject: subject, Code: code, Message: message}
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestNatsRequestReturnsRemoteError(t *testing.T) {
+	client := runNatsServer(t)
+
+	sub, err := client.Subscribe("users.get", func(data []byte) ([]byte, error) {
+		return nil, NewServiceError(404, "user not found")
+	})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	_, err = client.Request("users.get", map[string]string{"id": "1"}, 2*time.Second)
+	var remoteErr *RemoteError
+	if !errors.As(err, &remoteErr) {
+		t.Fatalf("expected RemoteError, got %v", err)
+	}
+	if remoteErr.Code != 404 || remoteErr.Message != "user not found" {
+		t.Fatalf("unexpected remote error %+v", remoteErr)
+	}
+	if errors.Is(err, nats.ErrTimeout) {
+		t.Fatal("request should not time out")
+	}
+}
@@ -0,0 +1,336 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Cabeceras agregadas a los mensajes reenviados al subject de dead-letter.
+const (
+	DeadLetterSubjectHeader    = "Goney-Original-Subject"
+	DeadLetterErrorHeader      = "Goney-Error"
+	DeadLetterDeliveriesHeader = "Goney-Deliveries"
+)
+
+// ErrTerminate indica que el mensaje no debe reintentarse; se envuelve con
+// fmt.Errorf("%w: ...", ErrTerminate) desde el handler.
+var ErrTerminate = errors.New("terminate message")
+
+// errMaxDeliveries es la causa del reenvío al dead-letter de un mensaje que
+// ya agotó MaxDeliver y se reentrega solo porque falló el primer reenvío.
+var errMaxDeliveries = errors.New("max deliveries exceeded")
+
+type StreamConfig struct {
+	Name     string        `json:"name"`
+	Subjects []string      `json:"subjects"`
+	Storage  string        `json:"storage,omitempty"` // "file" (por defecto) o "memory"
+	MaxAge   time.Duration `json:"max_age,omitempty"`
+	Replicas int           `json:"replicas,omitempty"`
+}
+
+type ConsumerConfig struct {
+	Stream        string `json:"stream"`
+	Durable       string `json:"durable"`
+	FilterSubject string `json:"filter_subject,omitempty"`
+
+	// Push consumers: los mensajes se entregan en DeliverSubject, repartidos
+	// entre los miembros de DeliverGroup si se indica.
+	Push           bool   `json:"push,omitempty"`
+	DeliverSubject string `json:"deliver_subject,omitempty"`
+	DeliverGroup   string `json:"deliver_group,omitempty"`
+
+	AckWait    time.Duration `json:"ack_wait,omitempty"`
+	MaxDeliver int           `json:"max_deliver,omitempty"`
+	// Backoff es la espera antes de cada reintento; el último valor se
+	// repite si hay más reintentos que valores.
+	Backoff []time.Duration `json:"backoff,omitempty"`
+	// DeadLetterSubject debe pertenecer a un stream: el reenvío espera el
+	// ack de JetStream y, si falla, el mensaje se reintenta en vez de
+	// perderse. Con dead-letter el límite de MaxDeliver lo aplica el
+	// cliente y el consumer del servidor no tiene límite, para que quede
+	// una reentrega con la que repetir el reenvío.
+	DeadLetterSubject string `json:"dead_letter_subject,omitempty"`
+
+	// Pull consumers
+	BatchSize    int           `json:"batch_size,omitempty"`
+	FetchTimeout time.Duration `json:"fetch_timeout,omitempty"`
+}
+
+type JetStreamConfig struct {
+	Streams   []StreamConfig   `json:"streams"`
+	Consumers []ConsumerConfig `json:"consumers"`
+}
+
+type JetStreamClient struct {
+	client *NatsClient
+	js     nats.JetStreamContext
+}
+
+func (c *NatsClient) JetStream() (*JetStreamClient, error) {
+	js, err := c.conn.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	return &JetStreamClient{client: c, js: js}, nil
+}
+
+// Setup crea o actualiza los streams y consumers declarados en la config.
+func (j *JetStreamClient) Setup(config JetStreamConfig) error {
+	for _, stream := range config.Streams {
+		if err := j.EnsureStream(stream); err != nil {
+			return err
+		}
+	}
+	for _, consumer := range config.Consumers {
+		if err := j.EnsureConsumer(consumer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *JetStreamClient) EnsureStream(config StreamConfig) error {
+	streamConfig := &nats.StreamConfig{
+		Name:     config.Name,
+		Subjects: config.Subjects,
+		Storage:  nats.FileStorage,
+		MaxAge:   config.MaxAge,
+		Replicas: config.Replicas,
+	}
+	if config.Storage == "memory" {
+		streamConfig.Storage = nats.MemoryStorage
+	}
+
+	_, err := j.js.StreamInfo(config.Name)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = j.js.AddStream(streamConfig)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = j.js.UpdateStream(streamConfig)
+	return err
+}
+
+func (j *JetStreamClient) EnsureConsumer(config ConsumerConfig) error {
+	consumerConfig := &nats.ConsumerConfig{
+		Durable:       config.Durable,
+		FilterSubject: config.FilterSubject,
+		AckPolicy:     nats.AckExplicitPolicy,
+		AckWait:       config.AckWait,
+		MaxDeliver:    config.MaxDeliver,
+	}
+	if config.DeadLetterSubject != "" && config.MaxDeliver > 0 {
+		consumerConfig.MaxDeliver = -1
+	}
+	if config.Push {
+		consumerConfig.DeliverSubject = deliverSubject(config)
+		consumerConfig.DeliverGroup = config.DeliverGroup
+	}
+
+	_, err := j.js.ConsumerInfo(config.Stream, config.Durable)
+	if errors.Is(err, nats.ErrConsumerNotFound) {
+		_, err = j.js.AddConsumer(config.Stream, consumerConfig)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = j.js.UpdateConsumer(config.Stream, consumerConfig)
+	return err
+}
+
+func deliverSubject(config ConsumerConfig) string {
+	if config.DeliverSubject != "" {
+		return config.DeliverSubject
+	}
+	return fmt.Sprintf("_DELIVER.%s.%s", config.Stream, config.Durable)
+}
+
+func (j *JetStreamClient) Publish(subject string, data interface{}) (*nats.PubAck, error) {
+	msg, err := j.client.newMsg(subject, data)
+	if err != nil {
+		return nil, err
+	}
+	return j.js.PublishMsg(msg)
+}
+
+func (j *JetStreamClient) PullSubscribe(config ConsumerConfig, handler NatsHandler) (*NatsSubscription, error) {
+	return j.PullSubscribeWithContext(config, adaptHandler(handler))
+}
+
+func (j *JetStreamClient) PushSubscribe(config ConsumerConfig, handler NatsHandler) (*NatsSubscription, error) {
+	return j.PushSubscribeWithContext(config, adaptHandler(handler))
+}
+
+// PullSubscribeWithContext se enlaza al consumer durable (creándolo si no
+// existe) y procesa lotes de mensajes hasta que se cancela la suscripción.
+func (j *JetStreamClient) PullSubscribeWithContext(config ConsumerConfig, handler ContextHandler) (*NatsSubscription, error) {
+	if err := j.EnsureConsumer(config); err != nil {
+		return nil, err
+	}
+
+	sub, err := j.js.PullSubscribe(config.FilterSubject, config.Durable, nats.Bind(config.Stream, config.Durable))
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+	fetchTimeout := config.FetchTimeout
+	if fetchTimeout <= 0 {
+		fetchTimeout = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(j.client.ctx)
+	done := make(chan struct{})
+	process := j.messageHandler(ctx, config, handler)
+
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			fetchCtx, fetchCancel := context.WithTimeout(ctx, fetchTimeout)
+			msgs, err := sub.Fetch(batchSize, nats.Context(fetchCtx))
+			fetchCancel()
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, nats.ErrTimeout) && ctx.Err() == nil {
+				log.Printf("JetStream fetch error on %s/%s: %v", config.Stream, config.Durable, err)
+				time.Sleep(time.Second)
+			}
+			for _, msg := range msgs {
+				// Tras cancelar, lo ya recibido se devuelve para que otro
+				// consumidor lo procese.
+				if ctx.Err() != nil {
+					msg.Nak()
+					continue
+				}
+				process(msg)
+			}
+		}
+	}()
+
+	return &NatsSubscription{subscription: sub, cancel: cancel, done: done}, nil
+}
+
+func (j *JetStreamClient) PushSubscribeWithContext(config ConsumerConfig, handler ContextHandler) (*NatsSubscription, error) {
+	config.Push = true
+	if err := j.EnsureConsumer(config); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(j.client.ctx)
+	opts := []nats.SubOpt{nats.Bind(config.Stream, config.Durable), nats.ManualAck()}
+
+	var sub *nats.Subscription
+	var err error
+	if config.DeliverGroup != "" {
+		sub, err = j.js.QueueSubscribe(config.FilterSubject, config.DeliverGroup, j.messageHandler(ctx, config, handler), opts...)
+	} else {
+		sub, err = j.js.Subscribe(config.FilterSubject, j.messageHandler(ctx, config, handler), opts...)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &NatsSubscription{subscription: sub, cancel: cancel}, nil
+}
+
+// messageHandler confirma el mensaje si el handler no devuelve error. En caso
+// de error lo reintenta con backoff y, al agotar MaxDeliver o recibir
+// ErrTerminate, lo reenvía al subject de dead-letter y lo termina. Si el
+// reenvío falla el mensaje se rechaza con Nak y, en las reentregas que
+// superan MaxDeliver, solo se repite el reenvío.
+func (j *JetStreamClient) messageHandler(ctx context.Context, config ConsumerConfig, handler ContextHandler) nats.MsgHandler {
+	endpoint := j.client.endpoint(handler)
+	return func(msg *nats.Msg) {
+		var delivered uint64 = 1
+		if meta, metaErr := msg.Metadata(); metaErr == nil {
+			delivered = meta.NumDelivered
+		}
+		if config.MaxDeliver > 0 && delivered > uint64(config.MaxDeliver) {
+			j.terminate(config, msg, errMaxDeliveries, uint64(config.MaxDeliver), delivered)
+			return
+		}
+
+		md := &Metadata{
+			Transport: "nats",
+			Operation: msg.Subject,
+			Subject:   msg.Subject,
+			Header:    msg.Header,
+		}
+		_, err := endpoint(ContextWithMetadata(ctx, md), msg.Data)
+		if err == nil {
+			msg.Ack()
+			return
+		}
+
+		lastAttempt := config.MaxDeliver > 0 && delivered >= uint64(config.MaxDeliver)
+		if errors.Is(err, ErrTerminate) || lastAttempt {
+			j.terminate(config, msg, err, delivered, delivered)
+			return
+		}
+
+		if delay := backoffDelay(config.Backoff, delivered); delay > 0 {
+			msg.NakWithDelay(delay)
+		} else {
+			msg.Nak()
+		}
+	}
+}
+
+// terminate reenvía el mensaje al dead-letter y lo termina; si el reenvío
+// falla lo rechaza para que el servidor lo vuelva a entregar.
+func (j *JetStreamClient) terminate(config ConsumerConfig, msg *nats.Msg, cause error, attempts, delivered uint64) {
+	if err := j.deadLetter(config, msg, cause, attempts); err != nil {
+		log.Printf("Error publishing to dead-letter subject %s: %v", config.DeadLetterSubject, err)
+		delay := backoffDelay(config.Backoff, delivered)
+		if delay <= 0 {
+			delay = time.Second
+		}
+		msg.NakWithDelay(delay)
+		return
+	}
+	msg.Term()
+}
+
+func backoffDelay(backoff []time.Duration, delivered uint64) time.Duration {
+	if len(backoff) == 0 {
+		return 0
+	}
+	index := int(delivered) - 1
+	if index >= len(backoff) {
+		index = len(backoff) - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	return backoff[index]
+}
+
+func (j *JetStreamClient) deadLetter(config ConsumerConfig, msg *nats.Msg, cause error, delivered uint64) error {
+	if config.DeadLetterSubject == "" {
+		return nil
+	}
+
+	dead := nats.NewMsg(config.DeadLetterSubject)
+	for key, values := range msg.Header {
+		for _, value := range values {
+			dead.Header.Add(key, value)
+		}
+	}
+	dead.Header.Set(DeadLetterSubjectHeader, msg.Subject)
+	dead.Header.Set(DeadLetterErrorHeader, cause.Error())
+	dead.Header.Set(DeadLetterDeliveriesHeader, strconv.FormatUint(delivered, 10))
+	dead.Data = msg.Data
+
+	_, err := j.js.PublishMsg(dead)
+	return err
+}
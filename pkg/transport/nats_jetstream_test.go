@@ -0,0 +1,341 @@
+package transport
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+func runNatsServer(t *testing.T) *NatsClient {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("creating nats server: %v", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+
+	client := NewNatsClient(ns.ClientURL())
+	if err := client.Connect(); err != nil {
+		t.Fatalf("connecting to nats: %v", err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+func setupOrdersStream(t *testing.T, client *NatsClient) *JetStreamClient {
+	t.Helper()
+
+	js, err := client.JetStream()
+	if err != nil {
+		t.Fatalf("JetStream: %v", err)
+	}
+	err = js.Setup(JetStreamConfig{
+		Streams: []StreamConfig{
+			{Name: "ORDERS", Subjects: []string{"orders.>"}, Storage: "memory"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	return js
+}
+
+func TestJetStreamPullSubscribeAcks(t *testing.T) {
+	client := runNatsServer(t)
+	js := setupOrdersStream(t, client)
+
+	received := make(chan string, 1)
+	sub, err := js.PullSubscribe(ConsumerConfig{
+		Stream:        "ORDERS",
+		Durable:       "orders-worker",
+		FilterSubject: "orders.created",
+		FetchTimeout:  200 * time.Millisecond,
+	}, func(data []byte) ([]byte, error) {
+		received <- string(data)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("PullSubscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if _, err := js.Publish("orders.created", map[string]string{"id": "1"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case data := <-received:
+		if data != `{"id":"1"}` {
+			t.Fatalf("unexpected payload %s", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not delivered")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		info, err := js.js.ConsumerInfo("ORDERS", "orders-worker")
+		if err != nil {
+			t.Fatalf("ConsumerInfo: %v", err)
+		}
+		if info.NumAckPending == 0 && info.AckFloor.Consumer == 1 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("message was not acknowledged")
+}
+
+func TestJetStreamMaxDeliverSendsToDeadLetter(t *testing.T) {
+	client := runNatsServer(t)
+	js := setupOrdersStream(t, client)
+
+	dead, err := client.conn.SubscribeSync("orders.dead")
+	if err != nil {
+		t.Fatalf("SubscribeSync: %v", err)
+	}
+
+	var attempts atomic.Int32
+	sub, err := js.PushSubscribe(ConsumerConfig{
+		Stream:            "ORDERS",
+		Durable:           "orders-failing",
+		FilterSubject:     "orders.failed",
+		MaxDeliver:        3,
+		Backoff:           []time.Duration{10 * time.Millisecond},
+		DeadLetterSubject: "orders.dead",
+	}, func(data []byte) ([]byte, error) {
+		attempts.Add(1)
+		return nil, errors.New("boom")
+	})
+	if err != nil {
+		t.Fatalf("PushSubscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if _, err := js.Publish("orders.failed", "payload"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	msg, err := dead.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("dead-letter message not received: %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+	if got := msg.Header.Get(DeadLetterSubjectHeader); got != "orders.failed" {
+		t.Fatalf("unexpected original subject header %q", got)
+	}
+	if got := msg.Header.Get(DeadLetterDeliveriesHeader); got != "3" {
+		t.Fatalf("unexpected deliveries header %q", got)
+	}
+}
+
+func TestJetStreamTerminateSkipsRedelivery(t *testing.T) {
+	client := runNatsServer(t)
+	js := setupOrdersStream(t, client)
+
+	dead, err := client.conn.SubscribeSync("orders.dead")
+	if err != nil {
+		t.Fatalf("SubscribeSync: %v", err)
+	}
+
+	attempts := make(chan struct{}, 10)
+	sub, err := js.PushSubscribe(ConsumerConfig{
+		Stream:            "ORDERS",
+		Durable:           "orders-poison",
+		FilterSubject:     "orders.poison",
+		MaxDeliver:        5,
+		DeadLetterSubject: "orders.dead",
+	}, func(data []byte) ([]byte, error) {
+		attempts <- struct{}{}
+		return nil, ErrTerminate
+	})
+	if err != nil {
+		t.Fatalf("PushSubscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if _, err := js.Publish("orders.poison", "payload"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, err := dead.NextMsg(5 * time.Second); err != nil {
+		t.Fatalf("dead-letter message not received: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if len(attempts) != 1 {
+		t.Fatalf("expected a single attempt, got %d", len(attempts))
+	}
+}
+
+func TestJetStreamDeadLetterFailureRedelivers(t *testing.T) {
+	client := runNatsServer(t)
+	js := setupOrdersStream(t, client)
+
+	// Ningún stream recoge "lost.dead", así que el reenvío no recibe ack y
+	// el mensaje no se debe terminar.
+	attempts := make(chan struct{}, 10)
+	sub, err := js.PushSubscribe(ConsumerConfig{
+		Stream:            "ORDERS",
+		Durable:           "orders-lost",
+		FilterSubject:     "orders.lost",
+		MaxDeliver:        5,
+		Backoff:           []time.Duration{10 * time.Millisecond},
+		DeadLetterSubject: "lost.dead",
+	}, func(data []byte) ([]byte, error) {
+		attempts <- struct{}{}
+		return nil, ErrTerminate
+	})
+	if err != nil {
+		t.Fatalf("PushSubscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if _, err := js.Publish("orders.lost", "payload"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-attempts:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("expected a redelivery after the dead-letter failure, got %d attempts", i)
+		}
+	}
+}
+
+func TestJetStreamDeadLetterFailureOnLastDeliveryKeepsMessage(t *testing.T) {
+	client := runNatsServer(t)
+	js := setupOrdersStream(t, client)
+
+	// El stream del dead-letter está lleno y rechaza mensajes nuevos, así que
+	// el reenvío tras la última entrega falla hasta que se vacía.
+	if _, err := js.js.AddStream(&nats.StreamConfig{
+		Name:     "LOST",
+		Subjects: []string{"lost.>"},
+		Storage:  nats.MemoryStorage,
+		MaxMsgs:  1,
+		Discard:  nats.DiscardNew,
+	}); err != nil {
+		t.Fatalf("AddStream: %v", err)
+	}
+	if _, err := js.Publish("lost.filler", "filler"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var attempts atomic.Int32
+	sub, err := js.PushSubscribe(ConsumerConfig{
+		Stream:            "ORDERS",
+		Durable:           "orders-exhausted",
+		FilterSubject:     "orders.exhausted",
+		MaxDeliver:        2,
+		Backoff:           []time.Duration{10 * time.Millisecond},
+		DeadLetterSubject: "lost.dead",
+	}, func(data []byte) ([]byte, error) {
+		attempts.Add(1)
+		return nil, errors.New("boom")
+	})
+	if err != nil {
+		t.Fatalf("PushSubscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	if _, err := js.Publish("orders.exhausted", "payload"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for attempts.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 attempts, got %d", attempts.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if err := js.js.PurgeStream("LOST"); err != nil {
+		t.Fatalf("PurgeStream: %v", err)
+	}
+	var msg *nats.RawStreamMsg
+	deadline = time.Now().Add(5 * time.Second)
+	for msg == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("message lost after the dead-letter failure")
+		}
+		msg, _ = js.js.GetLastMsg("LOST", "lost.dead")
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := msg.Header.Get(DeadLetterDeliveriesHeader); got != "2" {
+		t.Fatalf("unexpected deliveries header %q", got)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("handler called %d times, want 2", got)
+	}
+}
+
+func TestJetStreamPullStopsHandlingAfterUnsubscribe(t *testing.T) {
+	client := runNatsServer(t)
+	js := setupOrdersStream(t, client)
+
+	for i := 0; i < 3; i++ {
+		if _, err := js.Publish("orders.batch", i); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	var calls atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	sub, err := js.PullSubscribe(ConsumerConfig{
+		Stream:        "ORDERS",
+		Durable:       "orders-batch",
+		FilterSubject: "orders.batch",
+		BatchSize:     3,
+		FetchTimeout:  200 * time.Millisecond,
+	}, func(data []byte) ([]byte, error) {
+		calls.Add(1)
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("PullSubscribe: %v", err)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not delivered")
+	}
+	done := make(chan struct{})
+	go func() {
+		sub.Unsubscribe()
+		close(done)
+	}()
+	// Se espera a que la cancelación llegue antes de soltar el handler.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Unsubscribe did not return")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected 1 handler call, got %d", got)
+	}
+}
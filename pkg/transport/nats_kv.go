@@ -0,0 +1,297 @@
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var ErrKeyNotFound = nats.ErrKeyNotFound
+
+type KeyValueConfig struct {
+	Bucket      string        `json:"bucket"`
+	Description string        `json:"description,omitempty"`
+	TTL         time.Duration `json:"ttl,omitempty"`
+	History     uint8         `json:"history,omitempty"`
+	Storage     string        `json:"storage,omitempty"` // "file" (por defecto) o "memory"
+}
+
+type KeyValueEntry struct {
+	Key      string
+	Value    []byte
+	Revision uint64
+	Deleted  bool
+	Created  time.Time
+}
+
+type KeyValueStore struct {
+	kv nats.KeyValue
+}
+
+// KeyValue se enlaza al bucket indicado, creándolo si no existe.
+func (j *JetStreamClient) KeyValue(config KeyValueConfig) (*KeyValueStore, error) {
+	kv, err := j.js.KeyValue(config.Bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kvConfig := &nats.KeyValueConfig{
+			Bucket:      config.Bucket,
+			Description: config.Description,
+			TTL:         config.TTL,
+			History:     config.History,
+			Storage:     nats.FileStorage,
+		}
+		if config.Storage == "memory" {
+			kvConfig.Storage = nats.MemoryStorage
+		}
+		kv, err = j.js.CreateKeyValue(kvConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &KeyValueStore{kv: kv}, nil
+}
+
+func toKeyValueEntry(entry nats.KeyValueEntry) KeyValueEntry {
+	return KeyValueEntry{
+		Key:      entry.Key(),
+		Value:    entry.Value(),
+		Revision: entry.Revision(),
+		Deleted:  entry.Operation() != nats.KeyValuePut,
+		Created:  entry.Created(),
+	}
+}
+
+func (s *KeyValueStore) Get(key string) (*KeyValueEntry, error) {
+	entry, err := s.kv.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	result := toKeyValueEntry(entry)
+	return &result, nil
+}
+
+func (s *KeyValueStore) Put(key string, value []byte) (uint64, error) {
+	return s.kv.Put(key, value)
+}
+
+// Create solo escribe si la clave no existe.
+func (s *KeyValueStore) Create(key string, value []byte) (uint64, error) {
+	return s.kv.Create(key, value)
+}
+
+// Update escribe solo si la revisión actual coincide (compare-and-set).
+func (s *KeyValueStore) Update(key string, value []byte, revision uint64) (uint64, error) {
+	return s.kv.Update(key, value, revision)
+}
+
+func (s *KeyValueStore) Delete(key string) error {
+	return s.kv.Delete(key)
+}
+
+func (s *KeyValueStore) Keys() ([]string, error) {
+	keys, err := s.kv.Keys()
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return []string{}, nil
+	}
+	return keys, err
+}
+
+// Watch envía los valores actuales y los cambios posteriores de las claves
+// que coinciden con pattern (admite comodines de NATS) hasta que se cancela ctx.
+func (s *KeyValueStore) Watch(ctx context.Context, pattern string) (<-chan KeyValueEntry, error) {
+	watcher, err := s.kv.Watch(pattern, nats.Context(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan KeyValueEntry)
+	go func() {
+		defer close(updates)
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+				// nil marca el fin de los valores iniciales
+				if entry == nil {
+					continue
+				}
+				select {
+				case updates <- toKeyValueEntry(entry):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// adapterKey codifica claves arbitrarias (rutas, IPs) con caracteres válidos
+// para un bucket KV.
+func adapterKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+type cacheEnvelope struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Value     []byte    `json:"value"`
+}
+
+// KVCacheStore implementa guards.CacheStore sobre un bucket KV, guardando la
+// expiración junto al valor porque los buckets solo tienen TTL global.
+type KVCacheStore struct {
+	store *KeyValueStore
+}
+
+func NewKVCacheStore(store *KeyValueStore) *KVCacheStore {
+	return &KVCacheStore{store: store}
+}
+
+func (c *KVCacheStore) Get(key string) ([]byte, bool, error) {
+	entry, err := c.store.Get(adapterKey(key))
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var envelope cacheEnvelope
+	if err := json.Unmarshal(entry.Value, &envelope); err != nil {
+		return nil, false, err
+	}
+	if time.Now().After(envelope.ExpiresAt) {
+		c.store.Delete(adapterKey(key))
+		return nil, false, nil
+	}
+	return envelope.Value, true, nil
+}
+
+func (c *KVCacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	data, err := json.Marshal(cacheEnvelope{ExpiresAt: time.Now().Add(ttl), Value: value})
+	if err != nil {
+		return err
+	}
+	_, err = c.store.Put(adapterKey(key), data)
+	return err
+}
+
+func (c *KVCacheStore) Delete(key string) error {
+	return c.store.Delete(adapterKey(key))
+}
+
+type throttleEnvelope struct {
+	WindowStart time.Time `json:"window_start"`
+	Count       int       `json:"count"`
+}
+
+// KVThrottleStore implementa guards.ThrottleStore con incrementos CAS. Cada
+// actualización renueva el TTL de la clave, así que el inicio de la ventana
+// se guarda junto al contador y se reinicia cuando ha pasado el TTL del
+// bucket (sin TTL el contador nunca se reinicia).
+type KVThrottleStore struct {
+	store      *KeyValueStore
+	window     time.Duration
+	maxRetries int
+}
+
+func NewKVThrottleStore(store *KeyValueStore) *KVThrottleStore {
+	var window time.Duration
+	if status, err := store.kv.Status(); err == nil {
+		window = status.TTL()
+	}
+	return &KVThrottleStore{store: store, window: window, maxRetries: 10}
+}
+
+func (t *KVThrottleStore) Increment(key string) (int, error) {
+	key = adapterKey(key)
+	var lastErr error
+	for attempt := 0; attempt < t.maxRetries; attempt++ {
+		now := time.Now()
+		first, _ := json.Marshal(throttleEnvelope{WindowStart: now, Count: 1})
+
+		entry, err := t.store.Get(key)
+		if errors.Is(err, ErrKeyNotFound) {
+			if _, err := t.store.Create(key, first); err != nil {
+				lastErr = err
+				continue
+			}
+			return 1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		var envelope throttleEnvelope
+		if err := json.Unmarshal(entry.Value, &envelope); err != nil || (t.window > 0 && now.Sub(envelope.WindowStart) >= t.window) {
+			envelope = throttleEnvelope{WindowStart: now}
+		}
+		envelope.Count++
+		data, err := json.Marshal(envelope)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := t.store.Update(key, data, entry.Revision); err != nil {
+			lastErr = err
+			continue
+		}
+		return envelope.Count, nil
+	}
+	return 0, lastErr
+}
+
+// KVConfigSource expone un bucket KV como configuración distribuida.
+type KVConfigSource struct {
+	store *KeyValueStore
+}
+
+func NewKVConfigSource(store *KeyValueStore) *KVConfigSource {
+	return &KVConfigSource{store: store}
+}
+
+func (c *KVConfigSource) Load() (map[string]string, error) {
+	keys, err := c.store.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		entry, err := c.store.Get(key)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = string(entry.Value)
+	}
+	return values, nil
+}
+
+// Watch llama a onChange por cada clave modificada (value vacío si se
+// eliminó) hasta que se cancela ctx.
+func (c *KVConfigSource) Watch(ctx context.Context, onChange func(key, value string)) error {
+	updates, err := c.store.Watch(ctx, ">")
+	if err != nil {
+		return err
+	}
+	go func() {
+		for entry := range updates {
+			if entry.Deleted {
+				onChange(entry.Key, "")
+				continue
+			}
+			onChange(entry.Key, string(entry.Value))
+		}
+	}()
+	return nil
+}
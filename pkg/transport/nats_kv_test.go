@@ -0,0 +1,215 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Go-Ney/goney/pkg/guards"
+)
+
+var (
+	_ guards.CacheStore    = (*KVCacheStore)(nil)
+	_ guards.ThrottleStore = (*KVThrottleStore)(nil)
+)
+
+func newKeyValueStore(t *testing.T) *KeyValueStore {
+	t.Helper()
+
+	client := runNatsServer(t)
+	js, err := client.JetStream()
+	if err != nil {
+		t.Fatalf("JetStream: %v", err)
+	}
+	store, err := js.KeyValue(KeyValueConfig{Bucket: "goney", Storage: "memory"})
+	if err != nil {
+		t.Fatalf("KeyValue: %v", err)
+	}
+	return store
+}
+
+func TestKeyValueCompareAndSet(t *testing.T) {
+	store := newKeyValueStore(t)
+
+	revision, err := store.Create("feature", []byte("off"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := store.Create("feature", []byte("on")); err == nil {
+		t.Fatal("Create should fail for an existing key")
+	}
+	if _, err := store.Update("feature", []byte("on"), revision); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := store.Update("feature", []byte("off"), revision); err == nil {
+		t.Fatal("Update should fail with a stale revision")
+	}
+
+	entry, err := store.Get("feature")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(entry.Value) != "on" {
+		t.Fatalf("unexpected value %q", entry.Value)
+	}
+}
+
+func TestKVStoresForGuards(t *testing.T) {
+	store := newKeyValueStore(t)
+
+	cache := NewKVCacheStore(store)
+	if err := cache.Set("/api/v1/users?page=1", []byte(`[]`), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, found, err := cache.Get("/api/v1/users?page=1")
+	if err != nil || !found || !bytes.Equal(value, []byte(`[]`)) {
+		t.Fatalf("unexpected cache entry %q %v %v", value, found, err)
+	}
+	if err := cache.Set("expired", []byte(`{}`), -time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, found, _ := cache.Get("expired"); found {
+		t.Fatal("expired entry should not be returned")
+	}
+
+	throttle := NewKVThrottleStore(store)
+	for want := 1; want <= 3; want++ {
+		got, err := throttle.Increment("::1")
+		if err != nil {
+			t.Fatalf("Increment: %v", err)
+		}
+		if got != want {
+			t.Fatalf("expected %d, got %d", want, got)
+		}
+	}
+}
+
+func TestKVConfigSourceWatch(t *testing.T) {
+	store := newKeyValueStore(t)
+	store.Put("log_level", []byte("info"))
+
+	source := NewKVConfigSource(store)
+	values, err := source.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if values["log_level"] != "info" {
+		t.Fatalf("unexpected config %v", values)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string, 10)
+	if err := source.Watch(ctx, func(key, value string) { changes <- key + "=" + value }); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	store.Put("log_level", []byte("debug"))
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-changes:
+			if change == "log_level=debug" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("config change not received")
+		}
+	}
+}
+
+func TestObjectStoreRoundTrip(t *testing.T) {
+	client := runNatsServer(t)
+	js, err := client.JetStream()
+	if err != nil {
+		t.Fatalf("JetStream: %v", err)
+	}
+	objects, err := js.ObjectStore(ObjectStoreConfig{Bucket: "files", Storage: "memory"})
+	if err != nil {
+		t.Fatalf("ObjectStore: %v", err)
+	}
+
+	payload := bytes.Repeat([]byte("goney"), 100000)
+	if _, err := objects.Put("report.bin", bytes.NewReader(payload)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	data, err := objects.GetBytes("report.bin")
+	if err != nil {
+		t.Fatalf("GetBytes: %v", err)
+	}
+	if !bytes.Equal(data, payload) {
+		t.Fatal("object content mismatch")
+	}
+
+	list, err := objects.List()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("unexpected list %v %v", list, err)
+	}
+}
+
+func TestKVThrottleStoreResetsAfterWindow(t *testing.T) {
+	client := runNatsServer(t)
+	js, err := client.JetStream()
+	if err != nil {
+		t.Fatalf("JetStream: %v", err)
+	}
+	store, err := js.KeyValue(KeyValueConfig{Bucket: "throttle", Storage: "memory", TTL: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("KeyValue: %v", err)
+	}
+
+	// Con peticiones continuas la clave nunca expira por TTL; el contador
+	// se debe reiniciar igualmente al pasar la ventana.
+	throttle := NewKVThrottleStore(store)
+	var counts []int
+	for i := 0; i < 10; i++ {
+		count, err := throttle.Increment("::1")
+		if err != nil {
+			t.Fatalf("Increment: %v", err)
+		}
+		counts = append(counts, count)
+		time.Sleep(100 * time.Millisecond)
+	}
+	if counts[0] != 1 || counts[1] != 2 {
+		t.Fatalf("unexpected first counts %v", counts)
+	}
+	for _, count := range counts {
+		if count > 4 {
+			t.Fatalf("counter was not reset: %v", counts)
+		}
+	}
+}
+
+func TestObjectStoreMissingAndDeleted(t *testing.T) {
+	client := runNatsServer(t)
+	js, err := client.JetStream()
+	if err != nil {
+		t.Fatalf("JetStream: %v", err)
+	}
+	objects, err := js.ObjectStore(ObjectStoreConfig{Bucket: "files", Storage: "memory"})
+	if err != nil {
+		t.Fatalf("ObjectStore: %v", err)
+	}
+
+	if _, err := objects.GetBytes("missing.bin"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound, got %v", err)
+	}
+	if list, err := objects.List(); err != nil || len(list) != 0 {
+		t.Fatalf("unexpected list on empty bucket %v %v", list, err)
+	}
+
+	if _, err := objects.PutBytes("report.bin", []byte("goney")); err != nil {
+		t.Fatalf("PutBytes: %v", err)
+	}
+	if err := objects.Delete("report.bin"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := objects.GetBytes("report.bin"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound after Delete, got %v", err)
+	}
+	if list, err := objects.List(); err != nil || len(list) != 0 {
+		t.Fatalf("deleted object still listed %v %v", list, err)
+	}
+}
@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var ErrObjectNotFound = nats.ErrObjectNotFound
+
+type ObjectStoreConfig struct {
+	Bucket      string        `json:"bucket"`
+	Description string        `json:"description,omitempty"`
+	TTL         time.Duration `json:"ttl,omitempty"`
+	Storage     string        `json:"storage,omitempty"` // "file" (por defecto) o "memory"
+}
+
+type ObjectStore struct {
+	store nats.ObjectStore
+}
+
+// ObjectStore se enlaza al bucket de objetos indicado, creándolo si no existe.
+func (j *JetStreamClient) ObjectStore(config ObjectStoreConfig) (*ObjectStore, error) {
+	store, err := j.js.ObjectStore(config.Bucket)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		storeConfig := &nats.ObjectStoreConfig{
+			Bucket:      config.Bucket,
+			Description: config.Description,
+			TTL:         config.TTL,
+			Storage:     nats.FileStorage,
+		}
+		if config.Storage == "memory" {
+			storeConfig.Storage = nats.MemoryStorage
+		}
+		store, err = j.js.CreateObjectStore(storeConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectStore{store: store}, nil
+}
+
+func (o *ObjectStore) Put(name string, reader io.Reader) (*nats.ObjectInfo, error) {
+	return o.store.Put(&nats.ObjectMeta{Name: name}, reader)
+}
+
+func (o *ObjectStore) PutBytes(name string, data []byte) (*nats.ObjectInfo, error) {
+	return o.store.PutBytes(name, data)
+}
+
+func (o *ObjectStore) PutFile(path string) (*nats.ObjectInfo, error) {
+	return o.store.PutFile(path)
+}
+
+// Get devuelve un lector del objeto; el llamador debe cerrarlo.
+func (o *ObjectStore) Get(name string) (io.ReadCloser, error) {
+	return o.store.Get(name)
+}
+
+func (o *ObjectStore) GetBytes(name string) ([]byte, error) {
+	return o.store.GetBytes(name)
+}
+
+func (o *ObjectStore) GetFile(name, path string) error {
+	return o.store.GetFile(name, path)
+}
+
+func (o *ObjectStore) Delete(name string) error {
+	return o.store.Delete(name)
+}
+
+func (o *ObjectStore) List() ([]*nats.ObjectInfo, error) {
+	objects, err := o.store.List()
+	if errors.Is(err, nats.ErrNoObjectsFound) {
+		return []*nats.ObjectInfo{}, nil
+	}
+	return objects, err
+}
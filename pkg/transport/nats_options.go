@@ -0,0 +1,175 @@
+package transport
+
+import (
+	"crypto/tls"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type NatsOptions struct {
+	Servers []string
+	Name    string
+
+	// Autenticación: se usa la primera opción configurada en este orden.
+	CredentialsFile string
+	NKeyFile        string
+	Token           string
+	User            string
+	Password        string
+
+	TLS         *tls.Config
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	ReconnectWait   time.Duration
+	MaxReconnects   int
+	ReconnectJitter time.Duration
+	PingInterval    time.Duration
+	MaxPingsOut     int
+
+	// DrainOnClose hace que Close espere a que se procesen los mensajes
+	// pendientes antes de cerrar la conexión.
+	DrainOnClose bool
+	DrainTimeout time.Duration
+
+	// OldRequestStyle crea un inbox por petición en lugar de reutilizar el
+	// inbox compartido del cliente.
+	OldRequestStyle bool
+}
+
+func DefaultNatsOptions() NatsOptions {
+	return NatsOptions{
+		Servers:       []string{nats.DefaultURL},
+		ReconnectWait: 2 * time.Second,
+		MaxReconnects: 5,
+	}
+}
+
+type ConnectionState string
+
+const (
+	ConnectionConnected    ConnectionState = "connected"
+	ConnectionDisconnected ConnectionState = "disconnected"
+	ConnectionReconnected  ConnectionState = "reconnected"
+	ConnectionClosed       ConnectionState = "closed"
+	ConnectionError        ConnectionState = "error"
+)
+
+type ConnectionEvent struct {
+	State ConnectionState
+	URL   string
+	Err   error
+	Time  time.Time
+}
+
+var ErrNatsNotConnected = errors.New("nats: not connected")
+
+func (o NatsOptions) natsOptions(c *NatsClient) ([]nats.Option, error) {
+	opts := []nats.Option{
+		nats.Name(o.Name),
+		nats.ReconnectWait(o.ReconnectWait),
+		nats.MaxReconnects(o.MaxReconnects),
+		nats.ConnectHandler(func(nc *nats.Conn) {
+			c.emit(ConnectionConnected, nc, nil)
+		}),
+		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
+			log.Printf("NATS disconnected: %v", err)
+			c.emit(ConnectionDisconnected, nc, err)
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			log.Printf("NATS reconnected to %v", nc.ConnectedUrl())
+			c.emit(ConnectionReconnected, nc, nil)
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			c.emit(ConnectionClosed, nc, nc.LastError())
+			close(c.closed)
+		}),
+		nats.ErrorHandler(func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			log.Printf("NATS error: %v", err)
+			c.emit(ConnectionError, nc, err)
+		}),
+	}
+
+	if o.ReconnectJitter > 0 {
+		opts = append(opts, nats.ReconnectJitter(o.ReconnectJitter, o.ReconnectJitter))
+	}
+	if o.PingInterval > 0 {
+		opts = append(opts, nats.PingInterval(o.PingInterval))
+	}
+	if o.MaxPingsOut > 0 {
+		opts = append(opts, nats.MaxPingsOutstanding(o.MaxPingsOut))
+	}
+	if o.OldRequestStyle {
+		opts = append(opts, nats.UseOldRequestStyle())
+	}
+	if o.DrainTimeout > 0 {
+		opts = append(opts, nats.DrainTimeout(o.DrainTimeout))
+	}
+
+	switch {
+	case o.CredentialsFile != "":
+		opts = append(opts, nats.UserCredentials(o.CredentialsFile))
+	case o.NKeyFile != "":
+		nkeyOpt, err := nats.NkeyOptionFromSeed(o.NKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, nkeyOpt)
+	case o.Token != "":
+		opts = append(opts, nats.Token(o.Token))
+	case o.User != "":
+		opts = append(opts, nats.UserInfo(o.User, o.Password))
+	}
+
+	if o.TLS != nil {
+		opts = append(opts, nats.Secure(o.TLS))
+	}
+	if o.TLSCertFile != "" && o.TLSKeyFile != "" {
+		opts = append(opts, nats.ClientCert(o.TLSCertFile, o.TLSKeyFile))
+	}
+	if o.TLSCAFile != "" {
+		opts = append(opts, nats.RootCAs(o.TLSCAFile))
+	}
+
+	return opts, nil
+}
+
+func (o NatsOptions) url() string {
+	if len(o.Servers) == 0 {
+		return nats.DefaultURL
+	}
+	return strings.Join(o.Servers, ",")
+}
+
+// emit publica el evento sin bloquear; si nadie consume Events los eventos
+// se descartan.
+func (c *NatsClient) emit(state ConnectionState, nc *nats.Conn, err error) {
+	event := ConnectionEvent{State: state, URL: nc.ConnectedUrl(), Err: err, Time: time.Now()}
+	select {
+	case c.events <- event:
+	default:
+	}
+}
+
+// Events devuelve el canal con los cambios de estado de la conexión.
+func (c *NatsClient) Events() <-chan ConnectionEvent {
+	return c.events
+}
+
+func (c *NatsClient) IsConnected() bool {
+	return c.conn != nil && c.conn.IsConnected()
+}
+
+// HealthCheck devuelve un error si la conexión no está activa; pensado para
+// los indicadores de salud de la aplicación.
+func (c *NatsClient) HealthCheck() error {
+	if !c.IsConnected() {
+		return ErrNatsNotConnected
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type RequestManyOptions struct {
+	// MaxMessages detiene la recolección al recibir ese número de respuestas
+	// (contando las de error); 0 espera hasta el deadline del contexto.
+	MaxMessages int
+	// StallTimeout termina la recolección si, tras la primera respuesta, no
+	// llega otra en ese tiempo.
+	StallTimeout time.Duration
+}
+
+// RequestWithContext es Request cancelable con el contexto del llamador.
+func (c *NatsClient) RequestWithContext(ctx context.Context, subject string, data interface{}) (*nats.Msg, error) {
+	msg, err := c.newMsg(subject, data)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.conn.RequestMsgWithContext(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return response, decodeRemoteError(subject, response)
+}
+
+// DefaultRequestManyTimeout limita RequestMany cuando el contexto no tiene
+// deadline, para que no espere respuestas indefinidamente.
+var DefaultRequestManyTimeout = 5 * time.Second
+
+// RequestMany publica la petición y recolecta las respuestas de todos los
+// servicios suscritos (scatter-gather) hasta el deadline del contexto
+// (DefaultRequestManyTimeout si no tiene), MaxMessages o StallTimeout.
+// Alcanzar cualquiera de esos límites no es un error. Las respuestas de error
+// no se incluyen en el resultado: se devuelven como RemoteError unidos con
+// errors.Join junto a las respuestas válidas.
+func (c *NatsClient) RequestMany(ctx context.Context, subject string, data interface{}, options RequestManyOptions) ([]*nats.Msg, error) {
+	msg, err := c.newMsg(subject, data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultRequestManyTimeout)
+		defer cancel()
+	}
+
+	inbox := c.conn.NewRespInbox()
+	sub, err := c.conn.SubscribeSync(inbox)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	msg.Reply = inbox
+	if err := c.conn.PublishMsg(msg); err != nil {
+		return nil, err
+	}
+
+	var responses []*nats.Msg
+	var remoteErrs []error
+	for received := 0; options.MaxMessages <= 0 || received < options.MaxMessages; received++ {
+		stall := time.Duration(0)
+		if received > 0 {
+			stall = options.StallTimeout
+		}
+
+		response, err := nextResponse(ctx, sub, stall)
+		if errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+		if err != nil {
+			return responses, errors.Join(append(remoteErrs, err)...)
+		}
+		if len(response.Data) == 0 && response.Header.Get("Status") == "503" {
+			return nil, nats.ErrNoResponders
+		}
+		if err := decodeRemoteError(subject, response); err != nil {
+			remoteErrs = append(remoteErrs, err)
+			continue
+		}
+		responses = append(responses, response)
+	}
+	return responses, errors.Join(remoteErrs...)
+}
+
+func nextResponse(ctx context.Context, sub *nats.Subscription, stall time.Duration) (*nats.Msg, error) {
+	if stall > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, stall)
+		defer cancel()
+	}
+	return sub.NextMsgWithContext(ctx)
+}
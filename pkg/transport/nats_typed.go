@@ -0,0 +1,87 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Subscribe decodifica cada mensaje en T con el codec indicado por su
+// Content-Type antes de llamar al handler.
+func Subscribe[T any](c *NatsClient, subject string, handler func(ctx context.Context, msg T) error) (*NatsSubscription, error) {
+	return c.SubscribeWithContext(subject, typedHandler(c, func(ctx context.Context, msg T) (struct{}, error) {
+		return struct{}{}, handler(ctx, msg)
+	}, false))
+}
+
+func QueueSubscribe[T any](c *NatsClient, subject, queue string, handler func(ctx context.Context, msg T) error) (*NatsSubscription, error) {
+	return c.QueueSubscribeWithContext(subject, queue, typedHandler(c, func(ctx context.Context, msg T) (struct{}, error) {
+		return struct{}{}, handler(ctx, msg)
+	}, false))
+}
+
+// Handle responde peticiones request/reply tipadas; la respuesta se codifica
+// con el mismo codec que la petición.
+func Handle[Req, Resp any](c *NatsClient, subject string, handler func(ctx context.Context, req Req) (Resp, error)) (*NatsSubscription, error) {
+	return c.SubscribeWithContext(subject, typedHandler(c, handler, true))
+}
+
+func QueueHandle[Req, Resp any](c *NatsClient, subject, queue string, handler func(ctx context.Context, req Req) (Resp, error)) (*NatsSubscription, error) {
+	return c.QueueSubscribeWithContext(subject, queue, typedHandler(c, handler, true))
+}
+
+func typedHandler[Req, Resp any](c *NatsClient, handler func(ctx context.Context, req Req) (Resp, error), reply bool) ContextHandler {
+	return func(ctx context.Context, data []byte) ([]byte, error) {
+		var header nats.Header
+		if md, ok := MetadataFromContext(ctx); ok {
+			header = md.Header
+		}
+		codec, err := c.codecFor(header)
+		if err != nil {
+			return nil, NewServiceError(http.StatusUnsupportedMediaType, err.Error())
+		}
+
+		var req Req
+		if err := codec.Unmarshal(data, &req); err != nil {
+			return nil, NewServiceError(http.StatusBadRequest, err.Error())
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil || !reply {
+			return nil, err
+		}
+		return codec.Marshal(resp)
+	}
+}
+
+// RequestTyped envía req con el codec del cliente y decodifica la respuesta
+// según su Content-Type.
+func RequestTyped[Req, Resp any](c *NatsClient, subject string, req Req, timeout time.Duration) (Resp, error) {
+	var resp Resp
+	msg, err := c.Request(subject, req, timeout)
+	if err != nil {
+		return resp, err
+	}
+	return decodeResponse[Resp](c, msg)
+}
+
+func RequestTypedWithContext[Req, Resp any](ctx context.Context, c *NatsClient, subject string, req Req) (Resp, error) {
+	var resp Resp
+	msg, err := c.RequestWithContext(ctx, subject, req)
+	if err != nil {
+		return resp, err
+	}
+	return decodeResponse[Resp](c, msg)
+}
+
+func decodeResponse[Resp any](c *NatsClient, msg *nats.Msg) (Resp, error) {
+	var resp Resp
+	codec, err := c.codecFor(msg.Header)
+	if err != nil {
+		return resp, err
+	}
+	err = codec.Unmarshal(msg.Data, &resp)
+	return resp, err
+}
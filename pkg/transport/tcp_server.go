@@ -3,19 +3,37 @@ package transport
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
+	"time"
 )
 
+// defaultTLSHandshakeTimeout limita el handshake TLS de cada conexión para
+// que un cliente que no lo completa no retenga la conexión.
+const defaultTLSHandshakeTimeout = 10 * time.Second
+
+// maxPendingMessages es cuántos mensajes de una conexión pueden esperar a que
+// termine el que se está procesando.
+const maxPendingMessages = 64
+
 type TcpServer struct {
-	listener net.Listener
-	port     string
-	handlers map[string]TcpHandler
-	mu       sync.RWMutex
-	ctx      context.Context
-	cancel   context.CancelFunc
+	listener    net.Listener
+	port        string
+	handlers    map[string]ContextHandler
+	middlewares []Middleware
+	// endpoints es la cadena de cada acción, construida al registrarla y al
+	// agregar middlewares.
+	endpoints map[string]Endpoint
+	tlsConfig *tls.Config
+	// handshakeTimeout es el plazo del handshake TLS.
+	handshakeTimeout time.Duration
+	mu               sync.RWMutex
+	ctx              context.Context
+	cancel           context.CancelFunc
 }
 
 type TcpHandler func([]byte) ([]byte, error)
@@ -34,17 +52,39 @@ type TcpResponse struct {
 func NewTcpServer(port string) *TcpServer {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &TcpServer{
-		port:     port,
-		handlers: make(map[string]TcpHandler),
-		ctx:      ctx,
-		cancel:   cancel,
+		port:             port,
+		handlers:         make(map[string]ContextHandler),
+		endpoints:        make(map[string]Endpoint),
+		handshakeTimeout: defaultTLSHandshakeTimeout,
+		ctx:              ctx,
+		cancel:           cancel,
 	}
 }
 
 func (s *TcpServer) RegisterHandler(action string, handler TcpHandler) {
+	s.RegisterContextHandler(action, adaptHandler(handler))
+}
+
+func (s *TcpServer) RegisterContextHandler(action string, handler ContextHandler) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.handlers[action] = handler
+	s.endpoints[action] = withRecovery(s.middlewares)(handlerEndpoint(handler))
+}
+
+// Use agrega middlewares que se aplican a todas las acciones registradas.
+func (s *TcpServer) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+	for action, handler := range s.handlers {
+		s.endpoints[action] = withRecovery(s.middlewares)(handlerEndpoint(handler))
+	}
+}
+
+// SetTLSConfig activa TLS en el listener; debe llamarse antes de Start.
+func (s *TcpServer) SetTLSConfig(config *tls.Config) {
+	s.tlsConfig = config
 }
 
 func (s *TcpServer) Start() error {
@@ -52,7 +92,12 @@ func (s *TcpServer) Start() error {
 	if err != nil {
 		return err
 	}
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	s.mu.Lock()
 	s.listener = listener
+	s.mu.Unlock()
 
 	fmt.Printf("TCP Server listening on port %s\n", s.port)
 
@@ -73,22 +118,68 @@ func (s *TcpServer) Start() error {
 func (s *TcpServer) handleConnection(conn net.Conn) {
 	defer conn.Close()
 
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		data := scanner.Bytes()
-		response := s.processMessage(data)
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	// Cerrar la conexión al detener el servidor desbloquea el scanner
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
 
-		responseData, err := json.Marshal(response)
+	md := &Metadata{
+		Transport:  "tcp",
+		RemoteAddr: conn.RemoteAddr(),
+		LocalAddr:  conn.LocalAddr(),
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		handshakeCtx, handshakeCancel := context.WithTimeout(ctx, s.handshakeTimeout)
+		err := tlsConn.HandshakeContext(handshakeCtx)
+		handshakeCancel()
 		if err != nil {
-			continue
+			return
+		}
+		state := tlsConn.ConnectionState()
+		md.TLS = &state
+	}
+
+	// Los mensajes se procesan en orden fuera del bucle de lectura, que sigue
+	// leyendo para detectar que el cliente cerró la conexión.
+	pending := make(chan []byte, maxPendingMessages)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for data := range pending {
+			if ctx.Err() != nil {
+				continue
+			}
+			response := s.processMessage(ctx, md, data)
+
+			responseData, err := json.Marshal(response)
+			if err != nil {
+				continue
+			}
+
+			conn.Write(append(responseData, '\n'))
 		}
+	}()
 
-		conn.Write(responseData)
-		conn.Write([]byte("\n"))
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		data := append([]byte(nil), scanner.Bytes()...)
+		select {
+		case pending <- data:
+		case <-ctx.Done():
+		}
 	}
+
+	// EOF o error de lectura: se cancela el contexto del mensaje en curso.
+	cancel()
+	close(pending)
+	<-done
 }
 
-func (s *TcpServer) processMessage(data []byte) TcpResponse {
+func (s *TcpServer) processMessage(ctx context.Context, md *Metadata, data []byte) TcpResponse {
 	var msg TcpMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
 		return TcpResponse{
@@ -98,7 +189,7 @@ func (s *TcpServer) processMessage(data []byte) TcpResponse {
 	}
 
 	s.mu.RLock()
-	handler, exists := s.handlers[msg.Action]
+	endpoint, exists := s.endpoints[msg.Action]
 	s.mu.RUnlock()
 
 	if !exists {
@@ -108,7 +199,16 @@ func (s *TcpServer) processMessage(data []byte) TcpResponse {
 		}
 	}
 
-	result, err := handler(msg.Data)
+	msgMd := *md
+	msgMd.Operation = msg.Action
+
+	response, err := endpoint(ContextWithMetadata(ctx, &msgMd), []byte(msg.Data))
+	if errors.Is(err, ErrHandlerPanic) {
+		return TcpResponse{
+			Success: false,
+			Error:   ErrHandlerPanic.Error(),
+		}
+	}
 	if err != nil {
 		return TcpResponse{
 			Success: false,
@@ -117,7 +217,7 @@ func (s *TcpServer) processMessage(data []byte) TcpResponse {
 	}
 
 	var resultData interface{}
-	json.Unmarshal(result, &resultData)
+	json.Unmarshal(responseBytes(response), &resultData)
 
 	return TcpResponse{
 		Success: true,
@@ -125,10 +225,24 @@ func (s *TcpServer) processMessage(data []byte) TcpResponse {
 	}
 }
 
+// Addr devuelve la dirección en la que escucha el servidor, o nil si aún no
+// se ha iniciado.
+func (s *TcpServer) Addr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
 func (s *TcpServer) Stop() error {
 	s.cancel()
-	if s.listener != nil {
-		return s.listener.Close()
+	s.mu.RLock()
+	listener := s.listener
+	s.mu.RUnlock()
+	if listener != nil {
+		return listener.Close()
 	}
 	return nil
 }
@@ -196,4 +310,4 @@ func (c *TcpClient) Close() error {
 		return c.conn.Close()
 	}
 	return nil
-}
\ No newline at end of file
+}
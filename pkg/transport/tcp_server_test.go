@@ -0,0 +1,280 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runTcpServer arranca el servidor en un puerto libre y devuelve su
+// dirección en 127.0.0.1.
+func runTcpServer(t *testing.T, server *TcpServer) string {
+	t.Helper()
+
+	go server.Start()
+	t.Cleanup(func() { server.Stop() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.Addr() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("tcp server not listening")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	_, port, _ := net.SplitHostPort(server.Addr().String())
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+func sendTcpMessage(t *testing.T, conn net.Conn, action string, data interface{}) TcpResponse {
+	t.Helper()
+
+	payload, _ := json.Marshal(data)
+	msg, _ := json.Marshal(TcpMessage{Action: action, Data: payload})
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write(append(msg, '\n')); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() {
+		t.Fatalf("no response: %v", scanner.Err())
+	}
+	var response TcpResponse
+	if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return response
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "goney-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestTcpServerTLSRoundTrip(t *testing.T) {
+	cert := selfSignedCert(t)
+	server := NewTcpServer("0")
+	server.SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}})
+
+	var sawTLS bool
+	server.RegisterContextHandler("echo", func(ctx context.Context, data []byte) ([]byte, error) {
+		md, ok := MetadataFromContext(ctx)
+		sawTLS = ok && md.TLS != nil && md.TLS.HandshakeComplete
+		return data, nil
+	})
+	addr := runTcpServer(t, server)
+
+	roots := x509.NewCertPool()
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	roots.AddCert(leaf)
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	response := sendTcpMessage(t, conn, "echo", map[string]string{"msg": "hola"})
+	if !response.Success {
+		t.Fatalf("unexpected error %q", response.Error)
+	}
+	if data, _ := response.Data.(map[string]interface{}); data["msg"] != "hola" {
+		t.Fatalf("unexpected data %v", response.Data)
+	}
+	if !sawTLS {
+		t.Fatal("handler metadata has no TLS state")
+	}
+
+	// Un cliente sin TLS no llega al handler.
+	plain, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer plain.Close()
+	plain.SetDeadline(time.Now().Add(2 * time.Second))
+	plain.Write([]byte(`{"action":"echo","data":{}}` + "\n"))
+	if line, _ := bufio.NewReader(plain).ReadString('\n'); strings.Contains(line, "success") {
+		t.Fatalf("plain connection got a response: %q", line)
+	}
+}
+
+func TestTcpServerCancelsHandlerContextOnStop(t *testing.T) {
+	server := NewTcpServer("0")
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	server.RegisterContextHandler("wait", func(ctx context.Context, data []byte) ([]byte, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(2 * time.Second):
+			cancelled <- nil
+		}
+		return nil, ctx.Err()
+	})
+	addr := runTcpServer(t, server)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte(`{"action":"wait","data":{}}` + "\n"))
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler not called")
+	}
+	server.Stop()
+
+	select {
+	case err := <-cancelled:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+}
+
+func TestTcpServerCancelsHandlerContextWhenClientCloses(t *testing.T) {
+	server := NewTcpServer("0")
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	server.RegisterContextHandler("wait", func(ctx context.Context, data []byte) ([]byte, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(2 * time.Second):
+			cancelled <- nil
+		}
+		return nil, ctx.Err()
+	})
+	addr := runTcpServer(t, server)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	conn.Write([]byte(`{"action":"wait","data":{}}` + "\n"))
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler not called")
+	}
+	conn.Close()
+
+	select {
+	case err := <-cancelled:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("handler context was not cancelled")
+	}
+}
+
+func TestTcpServerAbortsStalledTLSHandshake(t *testing.T) {
+	server := NewTcpServer("0")
+	server.handshakeTimeout = 100 * time.Millisecond
+	server.SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}})
+	addr := runTcpServer(t, server)
+
+	// El cliente conecta pero nunca envía el ClientHello.
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil || isTimeout(err) {
+		t.Fatalf("expected the server to close the connection, got %v", err)
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func TestTcpServerAppliesMiddlewaresAddedAfterRegister(t *testing.T) {
+	server := NewTcpServer("0")
+	server.RegisterHandler("echo", func(data []byte) ([]byte, error) {
+		return data, nil
+	})
+	server.Use(func(next Endpoint) Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			return nil, errors.New("denied")
+		}
+	})
+	addr := runTcpServer(t, server)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	if response := sendTcpMessage(t, conn, "echo", "ping"); response.Success || response.Error != "denied" {
+		t.Fatalf("unexpected response %+v", response)
+	}
+}
+
+func TestTcpServerRecoversFromHandlerPanic(t *testing.T) {
+	server := NewTcpServer("0")
+	server.RegisterHandler("boom", func(data []byte) ([]byte, error) {
+		panic("boom")
+	})
+	server.RegisterHandler("echo", func(data []byte) ([]byte, error) {
+		return data, nil
+	})
+	addr := runTcpServer(t, server)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	response := sendTcpMessage(t, conn, "boom", nil)
+	if response.Success || response.Error != ErrHandlerPanic.Error() {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	// La conexión y el servidor siguen atendiendo.
+	if response := sendTcpMessage(t, conn, "echo", "ping"); !response.Success || response.Data != "ping" {
+		t.Fatalf("unexpected response after panic %+v", response)
+	}
+}